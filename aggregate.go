@@ -0,0 +1,394 @@
+package tracker
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+)
+
+// Trackers in separate processes are combined by having each child run an
+// Emitter that streams newline delimited JSON messages over a Unix socket to
+// a Collector in the parent:
+//
+//	{"type":"hello","id":"worker-1"}
+//	{"type":"update","trackers":[{"name":"bytes","current":512,"total":0}]}
+//	{"type":"bye"}
+//
+// Updates carry the change of each tracker since the previous update on the
+// same connection, so the first update after connecting carries full values.
+// The collector adds them to local trackers of the same name, keeping
+// account of what each child contributed.
+
+// CrashPolicy decides what a Collector does with the contribution of a
+// child that disconnects without saying goodbye
+type CrashPolicy int
+
+const (
+	// CrashFreeze keeps the child's contribution in the local trackers
+	CrashFreeze CrashPolicy = iota
+	// CrashSubtract takes the child's contribution back out
+	CrashSubtract
+)
+
+type message struct {
+	Type     string       `json:"type"`
+	ID       string       `json:"id,omitempty"`
+	Trackers []trackDelta `json:"trackers,omitempty"`
+}
+
+type trackDelta struct {
+	Name     string `json:"name"`
+	Current  int64  `json:"current"`
+	Total    int64  `json:"total"`
+	HasTotal bool   `json:"has_total,omitempty"`
+}
+
+// Emitter streams the Counters and Gauges of a Set to a Collector,
+// reconnecting whenever the connection is lost
+type Emitter interface {
+	SetClock(Clock)
+	Start()
+	Flush() error
+	Stop() error
+}
+
+type emitter struct {
+	set      Set
+	addr     string
+	id       string
+	interval time.Duration
+	clock    Clock
+	conn     net.Conn
+	last     map[string]trackDelta
+	ticker   Ticker
+	stopped  bool
+	lock     sync.Mutex
+}
+
+// NewEmitter returns an Emitter identified as id that sends the changes in
+// s to the collector listening on the Unix socket at addr every d. The id
+// must stay the same across reconnections of the same child. Intervals
+// that are not positive use DefaultInterval.
+func NewEmitter(s Set, addr, id string, d time.Duration) Emitter {
+	newEmitter := &emitter{
+		set:      s,
+		addr:     addr,
+		id:       id,
+		interval: validInterval(d),
+		clock:    realClock,
+	}
+	return newEmitter
+}
+
+func (e *emitter) SetClock(c Clock) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.clock = c
+}
+
+// Start sends updates every interval until Stop. Failed sends are retried,
+// with a new connection, on the next tick.
+func (e *emitter) Start() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.ticker != nil {
+		return
+	}
+	e.ticker = e.clock.NewTicker(e.interval, func(time.Time) {
+		e.Flush()
+	})
+}
+
+// Flush sends the changes since the last update, connecting first if needed
+func (e *emitter) Flush() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.flush()
+}
+
+// Stop sends a last update and says goodbye, so the collector keeps this
+// child's contribution as final. Stopping a stopped emitter does nothing.
+func (e *emitter) Stop() error {
+	e.lock.Lock()
+	ticker := e.ticker
+	e.ticker = nil
+	e.lock.Unlock()
+	if ticker != nil {
+		ticker.Stop()
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.stopped {
+		return nil
+	}
+	if err := e.flush(); err != nil {
+		return err
+	}
+	if err := e.send(message{Type: "bye"}); err != nil {
+		return err
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	e.stopped = true
+	return err
+}
+
+func (e *emitter) flush() error {
+	if e.conn == nil {
+		conn, err := net.Dial("unix", e.addr)
+		if err != nil {
+			return err
+		}
+		e.conn = conn
+		e.stopped = false
+		e.last = make(map[string]trackDelta)
+		if err := e.send(message{Type: "hello", ID: e.id}); err != nil {
+			return err
+		}
+	}
+	deltas := []trackDelta{}
+	now := make(map[string]trackDelta)
+	for _, rd := range e.set.Read() {
+		cur := trackDelta{Name: rd.Name, Current: rd.Current, Total: rd.Total, HasTotal: rd.HasTotal}
+		now[rd.Name] = cur
+		prev := e.last[rd.Name]
+		if cur.Current != prev.Current || cur.Total != prev.Total {
+			deltas = append(deltas, trackDelta{
+				Name:     rd.Name,
+				Current:  cur.Current - prev.Current,
+				Total:    cur.Total - prev.Total,
+				HasTotal: rd.HasTotal,
+			})
+		}
+	}
+	if len(deltas) == 0 {
+		return nil
+	}
+	if err := e.send(message{Type: "update", Trackers: deltas}); err != nil {
+		return err
+	}
+	e.last = now
+	return nil
+}
+
+// send writes m, dropping the connection on failure so the next flush
+// starts over with full values
+func (e *emitter) send(m message) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if _, err = e.conn.Write(append(b, '\n')); err != nil {
+		e.conn.Close()
+		e.conn = nil
+	}
+	return err
+}
+
+// Collector merges the trackers streamed by Emitters into a local Set,
+// creating Counters and Gauges by name as they first appear
+type Collector interface {
+	CrashPolicy(CrashPolicy)
+	Listen(addr string) error
+	Serve(net.Listener) error
+	Children() []string
+	Close() error
+}
+
+type collector struct {
+	set       Set
+	policy    CrashPolicy
+	children  map[string]*child
+	listeners []net.Listener
+	conns     map[net.Conn]struct{}
+	closed    bool
+	lock      sync.Mutex
+}
+
+// child is what a collector knows about one emitter
+type child struct {
+	contrib   map[string]*trackDelta
+	conn      net.Conn
+	connected bool
+}
+
+func NewCollector(s Set) Collector {
+	newCollector := &collector{
+		set:      s,
+		children: make(map[string]*child),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	return newCollector
+}
+
+func (c *collector) CrashPolicy(p CrashPolicy) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.policy = p
+}
+
+// Listen serves on a Unix socket at addr until Close
+func (c *collector) Listen(addr string) error {
+	l, err := net.Listen("unix", addr)
+	if err != nil {
+		return err
+	}
+	return c.Serve(l)
+}
+
+// Serve accepts emitters on l until Close
+func (c *collector) Serve(l net.Listener) error {
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return net.ErrClosed
+	}
+	c.listeners = append(c.listeners, l)
+	c.lock.Unlock()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			c.lock.Lock()
+			closed := c.closed
+			c.lock.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		c.lock.Lock()
+		c.conns[conn] = struct{}{}
+		c.lock.Unlock()
+		go c.handle(conn)
+	}
+}
+
+// Children returns the ids of the connected emitters
+func (c *collector) Children() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ids := []string{}
+	for id, ch := range c.children {
+		if ch.connected {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (c *collector) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.closed = true
+	var err error
+	for _, l := range c.listeners {
+		if lerr := l.Close(); err == nil {
+			err = lerr
+		}
+	}
+	for conn := range c.conns {
+		conn.Close()
+	}
+	return err
+}
+
+func (c *collector) handle(conn net.Conn) {
+	var ch *child
+	bye := false
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var m message
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			break
+		}
+		c.lock.Lock()
+		switch {
+		case m.Type == "hello":
+			ch = c.hello(m.ID, conn)
+		case ch == nil:
+			// anything before hello is a protocol error
+		case m.Type == "update":
+			for _, d := range m.Trackers {
+				c.apply(ch, d, 1)
+			}
+		case m.Type == "bye":
+			bye = true
+		}
+		c.lock.Unlock()
+		if ch == nil || bye {
+			break
+		}
+	}
+	conn.Close()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.conns, conn)
+	if ch == nil || ch.conn != conn {
+		return
+	}
+	ch.connected = false
+	// a child that said goodbye keeps its record, so that its contribution
+	// is replaced rather than added to if it connects again
+	if !bye && c.policy == CrashSubtract {
+		c.withdraw(ch)
+	}
+}
+
+// hello registers a connection for id. A child reconnecting after a crash
+// sends its full values again, so what it contributed before is taken out.
+func (c *collector) hello(id string, conn net.Conn) *child {
+	ch, ok := c.children[id]
+	if !ok {
+		ch = &child{contrib: make(map[string]*trackDelta)}
+		c.children[id] = ch
+	}
+	c.withdraw(ch)
+	ch.conn = conn
+	ch.connected = true
+	return ch
+}
+
+func (c *collector) withdraw(ch *child) {
+	for _, d := range ch.contrib {
+		c.apply(ch, *d, -1)
+	}
+	ch.contrib = make(map[string]*trackDelta)
+}
+
+// apply adds sign times d to the local tracker named d.Name and to the
+// child's account
+func (c *collector) apply(ch *child, d trackDelta, sign int64) {
+	contrib, ok := ch.contrib[d.Name]
+	if !ok {
+		contrib = &trackDelta{Name: d.Name}
+		ch.contrib[d.Name] = contrib
+	}
+	contrib.Current += sign * d.Current
+	contrib.Total += sign * d.Total
+	contrib.HasTotal = contrib.HasTotal || d.HasTotal
+	if sign < 0 {
+		delete(ch.contrib, d.Name)
+	}
+
+	if d.HasTotal {
+		g := c.set.Gauge(d.Name)
+		if g == nil {
+			g = NewGauge()
+			c.set.AddGauge(d.Name, g)
+		}
+		g.Total(sign * d.Total)
+		g.Current(sign * d.Current)
+		return
+	}
+	counter := c.set.Counter(d.Name)
+	if counter == nil {
+		counter = NewCounter()
+		c.set.AddCounter(d.Name, counter)
+	}
+	counter.Current(sign * d.Current)
+}
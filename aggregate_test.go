@@ -0,0 +1,204 @@
+package tracker
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/morrocker/tracker/trackertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func startCollector(t *testing.T, addr string, policy CrashPolicy) (Set, Collector) {
+	l, err := net.Listen("unix", addr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	s := NewSet()
+	c := NewCollector(s)
+	c.CrashPolicy(policy)
+	go c.Serve(l)
+	t.Cleanup(func() { c.Close() })
+	return s, c
+}
+
+func eventually(t *testing.T, want int64, got func() int64) {
+	assert.Eventually(t, func() bool { return got() == want }, time.Second, time.Millisecond)
+}
+
+func current(s Set, name string) func() int64 {
+	return func() int64 {
+		for _, rd := range s.Read() {
+			if rd.Name == name {
+				return rd.Current
+			}
+		}
+		return -1
+	}
+}
+
+// crash connects as id, sends one update and drops the connection
+func crash(t *testing.T, addr, id string, files int64) {
+	conn, err := net.Dial("unix", addr)
+	if !assert.NoError(t, err) {
+		return
+	}
+	conn.Write([]byte(`{"type":"hello","id":"` + id + `"}` + "\n"))
+	fmt.Fprintf(conn, `{"type":"update","trackers":[{"name":"files","current":%d}]}`+"\n", files)
+	conn.Close()
+}
+
+func TestCollector_Merge(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "tracker.sock")
+	local, c := startCollector(t, addr, CrashFreeze)
+
+	emitters := []Emitter{}
+	for _, id := range []string{"a", "b"} {
+		files := NewCounter()
+		bytes := NewGauge()
+		s := NewSet()
+		s.AddCounter("files", files)
+		s.AddGauge("bytes", bytes)
+		files.Current(3)
+		bytes.Total(100)
+		bytes.Current(40)
+		e := NewEmitter(s, addr, id, time.Second)
+		assert.NoError(t, e.Flush())
+		files.Current(2)
+		assert.NoError(t, e.Flush())
+		emitters = append(emitters, e)
+	}
+	eventually(t, 10, current(local, "files"))
+	eventually(t, 80, current(local, "bytes"))
+	_, total := local.Gauge("bytes").RawValues()
+	assert.Equal(t, int64(200), total)
+	assert.ElementsMatch(t, []string{"a", "b"}, c.Children())
+
+	for _, e := range emitters {
+		assert.NoError(t, e.Stop())
+	}
+	assert.Eventually(t, func() bool { return len(c.Children()) == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, int64(10), local.Counter("files").RawValue())
+}
+
+func TestCollector_Crash(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy CrashPolicy
+		want   int64
+	}{
+		{
+			name:   "freeze",
+			policy: CrashFreeze,
+			want:   7,
+		},
+		{
+			name:   "subtract",
+			policy: CrashSubtract,
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := filepath.Join(t.TempDir(), "tracker.sock")
+			local, c := startCollector(t, addr, tt.policy)
+			crash(t, addr, "a", 7)
+			eventually(t, tt.want, current(local, "files"))
+			assert.Eventually(t, func() bool { return len(c.Children()) == 0 }, time.Second, time.Millisecond)
+
+			// the restarted child sends its full values again, which
+			// replace rather than add to what it sent before
+			files := NewCounter()
+			s := NewSet()
+			s.AddCounter("files", files)
+			files.Current(9)
+			e := NewEmitter(s, addr, "a", time.Second)
+			assert.NoError(t, e.Flush())
+			eventually(t, 9, current(local, "files"))
+			assert.NoError(t, e.Stop())
+		})
+	}
+}
+
+func TestEmitter_Reconnect(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	addr := filepath.Join(t.TempDir(), "tracker.sock")
+	files := NewCounter()
+	s := NewSet()
+	s.AddCounter("files", files)
+	e := NewEmitter(s, addr, "a", time.Second)
+	e.SetClock(clk)
+	e.Start()
+
+	// nothing is listening yet, so the tick fails and is retried
+	files.Current(4)
+	clk.Advance(time.Second)
+
+	local, _ := startCollector(t, addr, CrashFreeze)
+	clk.Advance(time.Second)
+	eventually(t, 4, current(local, "files"))
+
+	files.Current(1)
+	clk.Advance(time.Second)
+	eventually(t, 5, current(local, "files"))
+	assert.NoError(t, e.Stop())
+}
+
+func TestEmitter_StopAfterPeerClosed(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "tracker.sock")
+	l, err := net.Listen("unix", addr)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	s := NewSet()
+	s.AddCounter("files", NewCounter())
+	e := NewEmitter(s, addr, "a", time.Second)
+	assert.NoError(t, e.Flush())
+	conn := <-accepted
+	conn.Close()
+
+	assert.NotPanics(t, func() {
+		e.Stop()
+	})
+}
+
+func TestEmitter_InvalidInterval(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "tracker.sock")
+	e := NewEmitter(NewSet(), addr, "a", 0)
+	assert.NotPanics(t, e.Start)
+	e.Stop()
+}
+
+func TestEmitter_StopStart(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "tracker.sock")
+	local, _ := startCollector(t, addr, CrashFreeze)
+	files := NewCounter()
+	s := NewSet()
+	s.AddCounter("files", files)
+	files.Current(5)
+	e := NewEmitter(s, addr, "a", time.Second)
+	e.SetClock(trackertest.NewClock(epoch))
+
+	assert.NoError(t, e.Stop())
+	eventually(t, 5, current(local, "files"))
+	assert.NoError(t, e.Stop())
+
+	e.Start()
+	files.Current(1)
+	assert.NoError(t, e.Flush())
+	eventually(t, 6, current(local, "files"))
+	assert.NoError(t, e.Stop())
+	assert.NoError(t, e.Stop())
+	assert.Never(t, func() bool { return current(local, "files")() != 6 }, 50*time.Millisecond, time.Millisecond)
+}
@@ -0,0 +1,277 @@
+//go:build unix
+
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// An arena file starts with a header followed by capacity slots of one
+// cache line each. A slot holds a tracker's name, its kind and its values;
+// the header's count is raised only after a slot is filled in, so readers
+// never see a half written slot.
+//
+//	header  magic "TRKARENA", version, capacity, count
+//	slot 0  name [40]byte, kind, current, total
+//	slot 1  ...
+
+const (
+	arenaMagic   = "TRKARENA"
+	arenaVersion = 1
+	arenaNameLen = 40
+)
+
+const (
+	slotCounter uint32 = iota + 1
+	slotGauge
+)
+
+var (
+	// ErrArenaFull is returned when every slot of an Arena is in use
+	ErrArenaFull = errors.New("tracker: arena is full")
+	// ErrArenaFormat is returned when opening a file that is not an arena
+	ErrArenaFormat = errors.New("tracker: not an arena file")
+)
+
+type arenaHeader struct {
+	magic    [8]byte
+	version  uint32
+	capacity uint32
+	count    uint32
+	_        [cacheLine - 20]byte
+}
+
+type arenaSlot struct {
+	name   [arenaNameLen]byte
+	kind   uint32
+	_      uint32
+	values [2]int64
+}
+
+// Arena creates Counters and Gauges whose values live in a memory mapped
+// file, so that other processes can read them with an ArenaReader. A file
+// has a single writing process.
+type Arena interface {
+	Counter(name string) (Counter, error)
+	Gauge(name string) (Gauge, error)
+	Close() error
+}
+
+type arena struct {
+	mapping
+	trackers map[string]any
+	lock     sync.Mutex
+}
+
+// mapping is an arena file mapped into memory
+type mapping struct {
+	data   []byte
+	header *arenaHeader
+	slots  []arenaSlot
+}
+
+// NewArena creates, or truncates, the file at path with room for capacity
+// trackers and maps it for writing
+func NewArena(path string, capacity int) (Arena, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	size := int(unsafe.Sizeof(arenaHeader{})) + capacity*int(unsafe.Sizeof(arenaSlot{}))
+	if err := f.Truncate(int64(size)); err != nil {
+		return nil, err
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	header := (*arenaHeader)(unsafe.Pointer(&data[0]))
+	copy(header.magic[:], arenaMagic)
+	header.version = arenaVersion
+	header.capacity = uint32(capacity)
+	newArena := &arena{
+		mapping:  newMapping(data, capacity),
+		trackers: make(map[string]any),
+	}
+	return newArena, nil
+}
+
+func newMapping(data []byte, capacity int) mapping {
+	m := mapping{
+		data:   data,
+		header: (*arenaHeader)(unsafe.Pointer(&data[0])),
+	}
+	if capacity > 0 {
+		first := (*arenaSlot)(unsafe.Pointer(&data[unsafe.Sizeof(arenaHeader{})]))
+		m.slots = unsafe.Slice(first, capacity)
+	}
+	return m
+}
+
+// Counter returns the Counter stored under name, adding it to the arena
+// the first time
+func (a *arena) Counter(name string) (Counter, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if t, ok := a.trackers[name]; ok {
+		if c, ok := t.(Counter); ok {
+			return c, nil
+		}
+		return nil, fmt.Errorf("tracker: %q is not a counter", name)
+	}
+	slot, err := a.add(name, slotCounter)
+	if err != nil {
+		return nil, err
+	}
+	newCounter := &counter[int64]{
+		shared: &slot.values[0],
+	}
+	a.trackers[name] = Counter(newCounter)
+	return newCounter, nil
+}
+
+// Gauge returns the Gauge stored under name, adding it to the arena the
+// first time. Arena gauges are unbounded.
+func (a *arena) Gauge(name string) (Gauge, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if t, ok := a.trackers[name]; ok {
+		if g, ok := t.(Gauge); ok {
+			return g, nil
+		}
+		return nil, fmt.Errorf("tracker: %q is not a gauge", name)
+	}
+	slot, err := a.add(name, slotGauge)
+	if err != nil {
+		return nil, err
+	}
+	newGauge := &gauge[int64]{
+		shared: &slot.values,
+	}
+	a.trackers[name] = Gauge(newGauge)
+	return newGauge, nil
+}
+
+// add fills in the next free slot and publishes it
+func (a *arena) add(name string, kind uint32) (*arenaSlot, error) {
+	if len(name) > arenaNameLen {
+		return nil, fmt.Errorf("tracker: arena names are limited to %d bytes: %q", arenaNameLen, name)
+	}
+	n := atomic.LoadUint32(&a.header.count)
+	if int(n) >= len(a.slots) {
+		return nil, ErrArenaFull
+	}
+	slot := &a.slots[n]
+	copy(slot.name[:], name)
+	slot.kind = kind
+	atomic.StoreUint32(&a.header.count, n+1)
+	return slot, nil
+}
+
+// Close unmaps the file. Trackers from the arena must not be used after
+// Close.
+func (a *arena) Close() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return syscall.Munmap(a.data)
+}
+
+// ArenaReader reads the trackers of an Arena written by another process
+type ArenaReader interface {
+	Names() []string
+	Read() []Reading
+	Close() error
+}
+
+type arenaReader struct {
+	mapping
+}
+
+// OpenArena maps the arena file at path for reading
+func OpenArena(path string) (ArenaReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := int(info.Size())
+	if size < int(unsafe.Sizeof(arenaHeader{})) {
+		return nil, ErrArenaFormat
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	header := (*arenaHeader)(unsafe.Pointer(&data[0]))
+	capacity := int(header.capacity)
+	want := int(unsafe.Sizeof(arenaHeader{})) + capacity*int(unsafe.Sizeof(arenaSlot{}))
+	if string(header.magic[:]) != arenaMagic || header.version != arenaVersion || size < want {
+		syscall.Munmap(data)
+		return nil, ErrArenaFormat
+	}
+	newReader := &arenaReader{
+		mapping: newMapping(data, capacity),
+	}
+	return newReader, nil
+}
+
+// published returns the slots the writer has filled in so far
+func (m *mapping) published() []arenaSlot {
+	n := int(atomic.LoadUint32(&m.header.count))
+	if n > len(m.slots) {
+		n = len(m.slots)
+	}
+	return m.slots[:n]
+}
+
+func (r *arenaReader) Names() []string {
+	names := []string{}
+	for i := range r.published() {
+		names = append(names, slotName(&r.slots[i]))
+	}
+	return names
+}
+
+// Read returns a Reading for every tracker in the order they were added,
+// formatted with the default units
+func (r *arenaReader) Read() []Reading {
+	slots := r.published()
+	readings := make([]Reading, 0, len(slots))
+	for i := range slots {
+		slot := &slots[i]
+		e := &entry{}
+		switch slot.kind {
+		case slotCounter:
+			e.counter = &counter[int64]{shared: &slot.values[0]}
+		case slotGauge:
+			e.gauge = &gauge[int64]{shared: &slot.values}
+		}
+		readings = append(readings, e.read(slotName(slot)))
+	}
+	return readings
+}
+
+func (r *arenaReader) Close() error {
+	return syscall.Munmap(r.data)
+}
+
+func slotName(slot *arenaSlot) string {
+	name := slot.name[:]
+	for i, b := range name {
+		if b == 0 {
+			return string(name[:i])
+		}
+	}
+	return string(name)
+}
@@ -0,0 +1,78 @@
+//go:build unix
+
+package tracker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArena_Layout(t *testing.T) {
+	assert.Equal(t, uintptr(cacheLine), unsafe.Sizeof(arenaHeader{}))
+	assert.Equal(t, uintptr(cacheLine), unsafe.Sizeof(arenaSlot{}))
+}
+
+func TestArena(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trackers")
+	a, err := NewArena(path, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer a.Close()
+
+	files, err := a.Counter("files")
+	assert.NoError(t, err)
+	bytes, err := a.Gauge("bytes")
+	assert.NoError(t, err)
+	files.Current(3)
+	bytes.SetTotal(200)
+	bytes.Current(50)
+
+	again, err := a.Counter("files")
+	assert.NoError(t, err)
+	assert.Equal(t, files, again)
+
+	r, err := OpenArena(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer r.Close()
+	assert.Equal(t, []string{"files", "bytes"}, r.Names())
+	assert.Equal(t, []Reading{
+		{Name: "files", Current: 3, Value: "3"},
+		{Name: "bytes", Current: 50, Value: "50", Total: 200, TotalValue: "200", Percent: 25, HasTotal: true},
+	}, r.Read())
+
+	// the reader sees later writes without reopening
+	files.Current(4)
+	assert.Equal(t, int64(7), r.Read()[0].Current)
+
+	_, err = a.Gauge("files")
+	assert.Error(t, err)
+	_, err = a.Counter("errors")
+	assert.True(t, errors.Is(err, ErrArenaFull))
+}
+
+func TestArena_Errors(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewArena(filepath.Join(dir, "trackers"), 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer a.Close()
+	_, err = a.Counter(strings.Repeat("x", arenaNameLen+1))
+	assert.Error(t, err)
+
+	bad := filepath.Join(dir, "bad")
+	assert.NoError(t, os.WriteFile(bad, make([]byte, 4096), 0o644))
+	_, err = OpenArena(bad)
+	assert.True(t, errors.Is(err, ErrArenaFormat))
+	_, err = OpenArena(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,118 @@
+package tracker
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/morrocker/tracker/trackertest"
+)
+
+// Run with go test -bench . -cpu 1,2,4,8 to see how the trackers scale
+
+func BenchmarkCounter_Current(b *testing.B) {
+	c := NewCounter()
+	for i := 0; i < b.N; i++ {
+		c.Current(1)
+	}
+}
+
+func BenchmarkCounter_CurrentParallel(b *testing.B) {
+	c := NewCounter()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Current(1)
+		}
+	})
+}
+
+func BenchmarkCounter_RawValueParallel(b *testing.B) {
+	c := NewCounter()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.RawValue()
+		}
+	})
+}
+
+func BenchmarkFloatCounter_CurrentParallel(b *testing.B) {
+	c := NewFloatCounter()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Current(0.5)
+		}
+	})
+}
+
+func BenchmarkGauge_Current(b *testing.B) {
+	g := NewGauge()
+	for i := 0; i < b.N; i++ {
+		g.Current(1)
+	}
+}
+
+func BenchmarkBoundedGauge_Current(b *testing.B) {
+	g := NewBoundedGauge(OverflowExtend)
+	for i := 0; i < b.N; i++ {
+		g.Current(1)
+	}
+}
+
+// benchmarkGaugeMixed has half the goroutines writing current while the
+// other half read total, which is where false sharing shows
+func benchmarkGaugeMixed(b *testing.B, g Gauge) {
+	g.SetTotal(1 << 40)
+	_, total := g.Pointers()
+	var id int32
+	b.RunParallel(func(pb *testing.PB) {
+		if atomic.AddInt32(&id, 1)%2 == 0 {
+			for pb.Next() {
+				atomic.LoadInt64(total)
+			}
+			return
+		}
+		for pb.Next() {
+			g.Current(1)
+		}
+	})
+}
+
+func BenchmarkGauge_MixedParallel(b *testing.B) {
+	benchmarkGaugeMixed(b, NewGauge())
+}
+
+func BenchmarkPaddedGauge_MixedParallel(b *testing.B) {
+	benchmarkGaugeMixed(b, NewPaddedGauge())
+}
+
+func BenchmarkSpeed_Measure(b *testing.B) {
+	var n int64
+	clk := trackertest.NewClock(epoch)
+	s := NewSpeed(&n, 10)
+	s.SetClock(clk)
+	for i := 0; i < b.N; i++ {
+		end := s.StartMeasure()
+		n += 100
+		clk.Advance(time.Second)
+		end()
+	}
+}
+
+func BenchmarkSpeed_RawRateParallel(b *testing.B) {
+	var n int64
+	clk := trackertest.NewClock(epoch)
+	s := NewSpeed(&n, 10)
+	s.SetClock(clk)
+	for i := 0; i < 10; i++ {
+		end := s.StartMeasure()
+		n += 100
+		clk.Advance(time.Second)
+		end()
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.RawRate()
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package tracker
+
+import (
+	"time"
+
+	"github.com/morrocker/tracker/internal/clock"
+)
+
+// Clock is the time source used by time-based trackers. Trackers use the
+// wall clock unless given another one through SetClock.
+type Clock = clock.Clock
+
+// Ticker is created by a Clock and delivers ticks at a fixed period
+type Ticker = clock.Ticker
+
+// Sleeper is an optional Clock method set. Throttles sleep with it when the
+// clock has one, and wait for a tick otherwise.
+type Sleeper = clock.Sleeper
+
+var realClock Clock = clock.Real{}
+
+// DefaultInterval replaces intervals that are zero or negative
+const DefaultInterval = time.Second
+
+// validInterval returns d, or DefaultInterval when d is not positive
+func validInterval(d time.Duration) time.Duration {
+	if d <= 0 {
+		return DefaultInterval
+	}
+	return d
+}
@@ -4,55 +4,148 @@ import (
 	"sync/atomic"
 )
 
-type Counter interface {
-	SetCurrent(n int64)
-	Current(n int64) (int64, error)
-	RawValue() int64
+// CounterOf counts values of type T
+type CounterOf[T Number] interface {
+	SetCurrent(n T)
+	Current(n T) (T, error)
+	RawValue() T
 	Value() string
-	UnitsFunc(func(int64) string)
+	UnitsFunc(func(T) string)
 	Reset()
-	Pointer() *int64
+	Pointer() *T
+	Swap(n T) T
+	Cursor() Cursor[T]
 }
 
-type counter struct {
-	current   int64
-	unitsFunc func(int64) string
+// Counter is a CounterOf int64
+type Counter = CounterOf[int64]
+
+type counter[T Number] struct {
+	current   T
+	shared    *T
+	monotonic bool
+	unitsFunc atomicUnits[T]
+	epoch     resetEpoch[T]
 }
 
+// NewCounter returns an up/down counter. It may be decremented but never
+// below zero.
 func NewCounter() Counter {
-	newCounter := &counter{
+	return NewCounterOf[int64]()
+}
+
+// NewMonotonicCounter returns a counter that only goes up until Reset.
+// Negative deltas are rejected with ErrDecrement and SetCurrent ignores
+// values below the current one.
+func NewMonotonicCounter() Counter {
+	return NewMonotonicCounterOf[int64]()
+}
+
+// NewCounterOf returns an up/down counter of T
+func NewCounterOf[T Number]() CounterOf[T] {
+	newCounter := &counter[T]{
 		current: 0,
 	}
 	return newCounter
 }
 
-func (c *counter) SetCurrent(n int64) {
-	atomic.CompareAndSwapInt64(&c.current, c.current, n)
+// NewMonotonicCounterOf returns a monotonic counter of T
+func NewMonotonicCounterOf[T Number]() CounterOf[T] {
+	newCounter := &counter[T]{
+		current:   0,
+		monotonic: true,
+	}
+	return newCounter
 }
 
-func (c *counter) Current(n int64) (int64, error) {
-	atomic.AddInt64(&c.current, n)
-	return c.current, nil
+// SetCurrent sets the value to n. Values below zero are ignored, as are
+// values below the current one on monotonic counters.
+func (c *counter[T]) SetCurrent(n T) {
+	if n < 0 {
+		return
+	}
+	if !c.monotonic {
+		store(c.Pointer(), n)
+		return
+	}
+	for {
+		cur := load(c.Pointer())
+		if n <= cur || cas(c.Pointer(), cur, n) {
+			return
+		}
+	}
 }
 
-func (c *counter) RawValue() int64 {
-	return c.current
+// Current adds n and returns the new value. Rejected updates leave the
+// counter unchanged and return an error wrapping ErrNegative, ErrDecrement
+// or ErrOverflow. Increments of int64 counters take a single atomic add and
+// are not checked for overflow.
+func (c *counter[T]) Current(n T) (T, error) {
+	current := c.Pointer()
+	if p, ok := any(current).(*int64); ok && n >= 0 {
+		return T(atomic.AddInt64(p, int64(n))), nil
+	}
+	if n < 0 && c.monotonic {
+		return 0, deltaError(load(current), n, ErrDecrement)
+	}
+	for {
+		cur := load(current)
+		next, err := add(cur, n)
+		if err != nil {
+			return 0, deltaError(cur, n, err)
+		}
+		if cas(current, cur, next) {
+			return next, nil
+		}
+	}
+}
+
+func (c *counter[T]) RawValue() T {
+	return load(c.Pointer())
 }
-func (c *counter) Value() string {
-	if c.unitsFunc == nil {
-		return "unitsFunction not set"
+
+func (c *counter[T]) Value() string {
+	return c.unitsFunc.format(load(c.Pointer()))
+}
+
+func (c *counter[T]) UnitsFunc(f func(T) string) {
+	c.unitsFunc.set(f)
+}
+
+// Reset zeroes the value. Cursors do not see it as a change.
+func (c *counter[T]) Reset() {
+	c.Swap(0)
+}
+
+// Swap sets the value to n and returns the old one, for collecting and
+// resetting in one step. Cursors do not see it as a change. Values below
+// zero are treated as zero.
+func (c *counter[T]) Swap(n T) T {
+	if n < 0 {
+		n = 0
 	}
-	return c.unitsFunc(c.current)
+	return c.epoch.swap(n, func(n T) T {
+		return exchange(c.Pointer(), n)
+	})
 }
 
-func (c *counter) UnitsFunc(f func(int64) string) {
-	c.unitsFunc = f
+// Cursor returns a Cursor over the value, starting from now. Reset and
+// Swap do not disturb it; SetCurrent shows up as a change.
+func (c *counter[T]) Cursor() Cursor[T] {
+	return newCursor(func() T {
+		return c.epoch.read(c.RawValue)
+	})
 }
 
-func (c *counter) Reset(){
-	atomic.CompareAndSwapInt64(&c.current, c.current, 0)
+func (c *counter[T]) resets() *resetEpoch[T] {
+	return &c.epoch
 }
 
-func (c *counter) Pointer() *int64 {
+// Pointer returns the address of the value. Floats must be accessed through
+// their bit patterns with the atomic package.
+func (c *counter[T]) Pointer() *T {
+	if c.shared != nil {
+		return c.shared
+	}
 	return &c.current
 }
@@ -1,6 +1,7 @@
 package tracker
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -22,7 +23,7 @@ func TestNewCounter(t *testing.T) {
 			args: args{
 				"object A",
 			},
-			want: &counter{
+			want: &counter[int64]{
 				current: 0,
 			},
 		},
@@ -71,7 +72,7 @@ func Test_counter_SetCurrent(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := &counter{
+			g := &counter[int64]{
 				current: tt.fields.current,
 			}
 			g.SetCurrent(tt.args.n)
@@ -81,6 +82,17 @@ func Test_counter_SetCurrent(t *testing.T) {
 	}
 }
 
+func Test_counter_SetCurrentNegative(t *testing.T) {
+	c := &counter[int64]{current: 40}
+	c.SetCurrent(-7)
+	assert.Equal(t, int64(40), c.current)
+
+	f := NewCounterOf[float64]()
+	f.SetCurrent(2.5)
+	f.SetCurrent(-0.5)
+	assert.Equal(t, 2.5, f.RawValue())
+}
+
 func Test_counter_Current(t *testing.T) {
 	type fields struct {
 		name    string
@@ -144,7 +156,7 @@ func Test_counter_Current(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := &counter{
+			g := &counter[int64]{
 				current: tt.fields.current,
 			}
 			got, err := g.Current(tt.args.n)
@@ -180,7 +192,7 @@ func Test_counter_RawValue(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := &counter{
+			g := &counter[int64]{
 				current: tt.fields.current,
 			}
 			if got := g.RawValue(); got != tt.want {
@@ -229,15 +241,15 @@ func Test_counter_Value(t *testing.T) {
 				name:    "simple counter",
 				current: 0,
 			},
-			want: "unitsFunction not set",
+			want: "0",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := &counter{
-				current:   tt.fields.current,
-				unitsFunc: tt.fields.unitsFunc,
+			g := &counter[int64]{
+				current: tt.fields.current,
 			}
+			g.UnitsFunc(tt.fields.unitsFunc)
 			if got := g.Value(); got != tt.want {
 				assert.Equal(t, got, tt.want)
 			}
@@ -304,12 +316,12 @@ func Test_counter_UnitsFunc(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := &counter{
-				current:   tt.fields.current,
-				unitsFunc: tt.fields.unitsFunc,
+			g := &counter[int64]{
+				current: tt.fields.current,
 			}
+			g.UnitsFunc(tt.fields.unitsFunc)
 			g.UnitsFunc(tt.args.f)
-			ptr1 := reflect.ValueOf(g.unitsFunc).Pointer()
+			ptr1 := reflect.ValueOf(g.unitsFunc.get()).Pointer()
 			ptr2 := reflect.ValueOf(tt.want.f).Pointer()
 			val := g.Value()
 			if tt.want.err {
@@ -322,3 +334,85 @@ func Test_counter_UnitsFunc(t *testing.T) {
 		})
 	}
 }
+
+func Test_counter_Monotonic(t *testing.T) {
+	type want struct {
+		ret     int64
+		current int64
+		err     error
+	}
+	tests := []struct {
+		name      string
+		monotonic bool
+		n         int64
+		want      want
+	}{
+		{
+			name:      "monotonic increment",
+			monotonic: true,
+			n:         5,
+			want: want{
+				ret:     15,
+				current: 15,
+			},
+		},
+		{
+			name:      "monotonic decrement",
+			monotonic: true,
+			n:         -5,
+			want: want{
+				ret:     0,
+				current: 10,
+				err:     ErrDecrement,
+			},
+		},
+		{
+			name:      "up/down decrement",
+			monotonic: false,
+			n:         -5,
+			want: want{
+				ret:     5,
+				current: 5,
+			},
+		},
+		{
+			name:      "up/down below zero",
+			monotonic: false,
+			n:         -11,
+			want: want{
+				ret:     0,
+				current: 10,
+				err:     ErrNegative,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &counter[int64]{
+				current:   10,
+				monotonic: tt.monotonic,
+			}
+			got, err := c.Current(tt.n)
+			assert.Equal(t, tt.want.ret, got)
+			assert.Equal(t, tt.want.current, c.current)
+			if tt.want.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tt.want.err))
+			var de *DeltaError
+			assert.True(t, errors.As(err, &de))
+			assert.Equal(t, int64(10), de.Current)
+			assert.Equal(t, tt.n, de.Delta)
+		})
+	}
+}
+
+func Test_counter_MonotonicSetCurrent(t *testing.T) {
+	c := NewMonotonicCounter()
+	c.SetCurrent(20)
+	c.SetCurrent(5)
+	assert.Equal(t, int64(20), c.RawValue())
+	c.Reset()
+	assert.Equal(t, int64(0), c.RawValue())
+}
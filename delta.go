@@ -0,0 +1,85 @@
+package tracker
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Cursor reports how much a tracker's current value changed between calls
+// to Delta, for exporters that push per interval deltas. Each consumer
+// keeps its own cursor.
+type Cursor[T Number] interface {
+	Delta() T
+}
+
+type cursor[T Number] struct {
+	read func() T
+	last T
+	lock sync.Mutex
+}
+
+// newCursor returns a cursor over read, starting from its current value
+func newCursor[T Number](read func() T) Cursor[T] {
+	newCursor := &cursor[T]{
+		read: read,
+		last: read(),
+	}
+	return newCursor
+}
+
+// Delta returns the change since the previous call, or since the cursor
+// was made
+func (c *cursor[T]) Delta() T {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	v := c.read()
+	d := v - c.last
+	c.last = v
+	return d
+}
+
+// resetEpoch lets Reset and Swap move a value without disturbing cursors.
+// Cursors read the value plus offset, and offset takes up whatever a reset
+// or swap removes. seq is odd while one is in progress, so that readers
+// never see the new value with the old offset.
+type resetEpoch[T Number] struct {
+	seq    uint64
+	offset T
+}
+
+// swap sets the value to n with exchange, which returns the old value
+func (e *resetEpoch[T]) swap(n T, exchange func(T) T) T {
+	for {
+		seq := atomic.LoadUint64(&e.seq)
+		if seq%2 == 0 && atomic.CompareAndSwapUint64(&e.seq, seq, seq+1) {
+			break
+		}
+		runtime.Gosched()
+	}
+	old := exchange(n)
+	store(&e.offset, load(&e.offset)+old-n)
+	atomic.AddUint64(&e.seq, 1)
+	return old
+}
+
+// read returns value() plus the offset
+func (e *resetEpoch[T]) read(value func() T) T {
+	v, _, offset := e.snapshot(value)
+	return v + offset
+}
+
+// snapshot returns value() along with the sequence number and offset it
+// was read under. The sequence number changes with every reset or swap.
+func (e *resetEpoch[T]) snapshot(value func() T) (T, uint64, T) {
+	for {
+		seq := atomic.LoadUint64(&e.seq)
+		if seq%2 == 0 {
+			v, offset := value(), load(&e.offset)
+			if atomic.LoadUint64(&e.seq) == seq {
+				return v, seq, offset
+			}
+		}
+		runtime.Gosched()
+	}
+}
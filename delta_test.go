@@ -0,0 +1,168 @@
+package tracker
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	c := NewCounter()
+	c.Current(5)
+	a := c.Cursor()
+	c.Current(10)
+	b := c.Cursor()
+	c.Current(3)
+	assert.Equal(t, int64(13), a.Delta())
+	assert.Equal(t, int64(0), a.Delta())
+
+	// resets and swaps are not changes, so nothing is lost or negative
+	c.Reset()
+	c.Current(4)
+	assert.Equal(t, int64(4), c.Swap(1))
+	c.Current(2)
+	assert.Equal(t, int64(6), a.Delta())
+	assert.Equal(t, int64(9), b.Delta())
+	assert.Equal(t, int64(3), c.RawValue())
+
+	c.SetCurrent(1)
+	assert.Equal(t, int64(-2), a.Delta())
+}
+
+func TestCursor_Gauge(t *testing.T) {
+	g := NewGauge()
+	g.SetTotal(100)
+	cur := g.Cursor()
+	g.Current(40)
+	g.Current(-10)
+	assert.Equal(t, int64(30), cur.Delta())
+	assert.Equal(t, int64(30), g.Swap(0))
+	g.Current(5)
+	g.Reset()
+	assert.Equal(t, int64(5), cur.Delta())
+	_, total := g.RawValues()
+	assert.Equal(t, int64(0), total)
+}
+
+func TestSwap_Checks(t *testing.T) {
+	c := NewMonotonicCounter()
+	c.Current(4)
+	assert.Equal(t, int64(4), c.Swap(-5))
+	assert.Equal(t, int64(0), c.RawValue())
+
+	sc := NewShardedCounter(2)
+	sc.Current(4)
+	assert.Equal(t, int64(4), sc.Swap(-5))
+	assert.Equal(t, int64(0), sc.RawValue())
+
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		n      int64
+		old    int64
+		curr   int64
+		total  int64
+	}{
+		{
+			name:   "error past total",
+			policy: OverflowError,
+			n:      500,
+			old:    40,
+			curr:   40,
+			total:  100,
+		},
+		{
+			name:   "error below zero",
+			policy: OverflowError,
+			n:      -3,
+			old:    40,
+			curr:   0,
+			total:  100,
+		},
+		{
+			name:   "clamp past total",
+			policy: OverflowClamp,
+			n:      500,
+			old:    40,
+			curr:   100,
+			total:  100,
+		},
+		{
+			name:   "extend past total",
+			policy: OverflowExtend,
+			n:      500,
+			old:    40,
+			curr:   500,
+			total:  500,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewBoundedGauge(tt.policy)
+			g.SetTotal(100)
+			g.SetCurrent(40)
+			assert.Equal(t, tt.old, g.Swap(tt.n))
+			curr, total := g.RawValues()
+			assert.Equal(t, tt.curr, curr)
+			assert.Equal(t, tt.total, total)
+		})
+	}
+}
+
+func TestSwap_Lifetime(t *testing.T) {
+	g := NewGauge()
+	g.SetTotal(100)
+	g.Swap(30)
+	assert.False(t, g.Started().IsZero())
+	assert.True(t, g.Finished().IsZero())
+	g.Swap(100)
+	assert.False(t, g.Finished().IsZero())
+}
+
+func TestCursor_Concurrent(t *testing.T) {
+	const writers, adds = 4, 1000
+	tests := []struct {
+		name    string
+		counter Counter
+	}{
+		{
+			name:    "counter",
+			counter: NewCounter(),
+		},
+		{
+			name:    "sharded",
+			counter: NewShardedCounter(4),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.counter
+			cur := c.Cursor()
+			var wg sync.WaitGroup
+			for i := 0; i < writers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < adds; j++ {
+						c.Current(1)
+					}
+				}()
+			}
+			done := make(chan struct{})
+			var deltas, swapped int64
+			go func() {
+				defer close(done)
+				for i := 0; i < 100; i++ {
+					deltas += cur.Delta()
+					swapped += c.Swap(0)
+				}
+			}()
+			wg.Wait()
+			<-done
+			deltas += cur.Delta()
+			assert.Equal(t, int64(writers*adds), deltas)
+			assert.Equal(t, int64(writers*adds), swapped+c.Swap(0))
+		})
+	}
+}
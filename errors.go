@@ -0,0 +1,38 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNegative is returned when an update would take a value below zero
+	ErrNegative = errors.New("tracker: value would go below zero")
+	// ErrDecrement is returned when a monotonic counter is asked to go down
+	ErrDecrement = errors.New("tracker: monotonic counter cannot decrease")
+	// ErrExceedsTotal is returned when a bounded gauge's current value would
+	// go past its total
+	ErrExceedsTotal = errors.New("tracker: current would exceed total")
+	// ErrOverflow is returned when an update would overflow an int64
+	ErrOverflow = errors.New("tracker: value would overflow")
+	// ErrTransition is returned when a Task is asked to move to a state it
+	// cannot reach from its current one
+	ErrTransition = errors.New("tracker: invalid task state transition")
+)
+
+// DeltaError reports an update that was rejected by an integer tracker.
+// It wraps one of the package's sentinel errors, so callers can test it
+// with errors.Is. Float trackers return the sentinel directly.
+type DeltaError struct {
+	Current int64
+	Delta   int64
+	Err     error
+}
+
+func (e *DeltaError) Error() string {
+	return fmt.Sprintf("%v (current %d, delta %d)", e.Err, e.Current, e.Delta)
+}
+
+func (e *DeltaError) Unwrap() error {
+	return e.Err
+}
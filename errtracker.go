@@ -0,0 +1,222 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OtherErrors is the category of errors that match no category
+const OtherErrors = "other"
+
+// ErrorSample is an error kept by an ErrorTracker along with when it was
+// added
+type ErrorSample struct {
+	Time time.Time
+	Err  error
+}
+
+// ErrorCount is the number of errors in a category and the most recent of
+// them, oldest first
+type ErrorCount struct {
+	Category string
+	Count    int64
+	Samples  []ErrorSample
+}
+
+// ErrorTracker counts errors by category and keeps the last few of each
+type ErrorTracker interface {
+	Category(name string, target error)
+	CategoryFunc(name string, match func(error) bool)
+	Classifier(func(error) string)
+	SampleSize(n uint)
+	SetClock(Clock)
+	Add(err error) string
+	Count(category string) int64
+	Counts() []ErrorCount
+	Total() int64
+	Reset()
+	Summary() string
+}
+
+type errorTracker struct {
+	matchers   []errorMatcher
+	classifier func(error) string
+	size       uint
+	clock      Clock
+	categories map[string]*ErrorCount
+	order      []string
+	total      int64
+	lock       sync.Mutex
+}
+
+type errorMatcher struct {
+	name  string
+	match func(error) bool
+}
+
+// NewErrorTracker returns an ErrorTracker keeping n samples per category
+func NewErrorTracker(n uint) ErrorTracker {
+	newTracker := &errorTracker{
+		size:       n,
+		clock:      realClock,
+		categories: make(map[string]*ErrorCount),
+	}
+	return newTracker
+}
+
+// MatchAs returns a match function for CategoryFunc that accepts errors
+// with an E in their chain, as errors.As finds them
+func MatchAs[E error]() func(error) bool {
+	return func(err error) bool {
+		var target E
+		return errors.As(err, &target)
+	}
+}
+
+// Category files errors matching target with errors.Is under name.
+// Categories are tried in the order they were added.
+func (t *errorTracker) Category(name string, target error) {
+	t.CategoryFunc(name, func(err error) bool {
+		return errors.Is(err, target)
+	})
+}
+
+// CategoryFunc files errors for which match returns true under name
+func (t *errorTracker) CategoryFunc(name string, match func(error) bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.matchers = append(t.matchers, errorMatcher{name: name, match: match})
+	t.category(name)
+}
+
+// Classifier sets a function naming the category of errors that match no
+// category. Errors it returns "" for are counted as OtherErrors.
+func (t *errorTracker) Classifier(f func(error) string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.classifier = f
+}
+
+// SampleSize sets the number of samples kept per category, dropping the
+// oldest ones beyond it
+func (t *errorTracker) SampleSize(n uint) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.size = n
+	for _, c := range t.categories {
+		c.Samples = trimSamples(c.Samples, n)
+	}
+}
+
+func (t *errorTracker) SetClock(c Clock) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.clock = c
+}
+
+// Add counts err and returns its category. Nil errors are ignored.
+func (t *errorTracker) Add(err error) string {
+	if err == nil {
+		return ""
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	name := t.classify(err)
+	c := t.category(name)
+	c.Count++
+	t.total++
+	c.Samples = trimSamples(append(c.Samples, ErrorSample{Time: t.clock.Now(), Err: err}), t.size)
+	return name
+}
+
+func (t *errorTracker) classify(err error) string {
+	for _, m := range t.matchers {
+		if m.match(err) {
+			return m.name
+		}
+	}
+	if t.classifier != nil {
+		if name := t.classifier(err); name != "" {
+			return name
+		}
+	}
+	return OtherErrors
+}
+
+func (t *errorTracker) category(name string) *ErrorCount {
+	c, ok := t.categories[name]
+	if !ok {
+		c = &ErrorCount{Category: name}
+		t.categories[name] = c
+		t.order = append(t.order, name)
+	}
+	return c
+}
+
+func trimSamples(samples []ErrorSample, n uint) []ErrorSample {
+	if uint(len(samples)) <= n {
+		return samples
+	}
+	return append([]ErrorSample(nil), samples[uint(len(samples))-n:]...)
+}
+
+func (t *errorTracker) Count(category string) int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if c, ok := t.categories[category]; ok {
+		return c.Count
+	}
+	return 0
+}
+
+// Counts returns the categories that have errors, in the order they were
+// added or first seen
+func (t *errorTracker) Counts() []ErrorCount {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	counts := []ErrorCount{}
+	for _, name := range t.order {
+		c := t.categories[name]
+		if c.Count == 0 {
+			continue
+		}
+		samples := make([]ErrorSample, len(c.Samples))
+		copy(samples, c.Samples)
+		counts = append(counts, ErrorCount{Category: name, Count: c.Count, Samples: samples})
+	}
+	return counts
+}
+
+func (t *errorTracker) Total() int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.total
+}
+
+// Reset clears the counts and samples but keeps the categories
+func (t *errorTracker) Reset() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, c := range t.categories {
+		c.Count = 0
+		c.Samples = nil
+	}
+	t.total = 0
+}
+
+// Summary describes each category with its most recent sample, as in
+// "12 permission denied, e.g. open /path: permission denied"
+func (t *errorTracker) Summary() string {
+	parts := []string{}
+	for _, c := range t.Counts() {
+		part := fmt.Sprintf("%d %s", c.Count, c.Category)
+		if len(c.Samples) > 0 {
+			part += fmt.Sprintf(", e.g. %v", c.Samples[len(c.Samples)-1].Err)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
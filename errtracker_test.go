@@ -0,0 +1,97 @@
+package tracker
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morrocker/tracker/trackertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorTracker_Classify(t *testing.T) {
+	et := NewErrorTracker(2)
+	et.Category("permission denied", fs.ErrPermission)
+	et.CategoryFunc("path", MatchAs[*fs.PathError]())
+	et.Classifier(func(err error) string {
+		if strings.Contains(err.Error(), "timeout") {
+			return "timeout"
+		}
+		return ""
+	})
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "errors.Is",
+			err:  &fs.PathError{Op: "open", Path: "/root", Err: fs.ErrPermission},
+			want: "permission denied",
+		},
+		{
+			name: "errors.As",
+			err:  &fs.PathError{Op: "open", Path: "/missing", Err: fs.ErrNotExist},
+			want: "path",
+		},
+		{
+			name: "classifier",
+			err:  errors.New("read timeout"),
+			want: "timeout",
+		},
+		{
+			name: "other",
+			err:  errors.New("boom"),
+			want: OtherErrors,
+		},
+		{
+			name: "nil",
+			err:  nil,
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, et.Add(tt.err))
+		})
+	}
+	assert.Equal(t, int64(4), et.Total())
+	assert.Equal(t, int64(1), et.Count("timeout"))
+	assert.Equal(t, int64(0), et.Count("missing"))
+}
+
+func TestErrorTracker_Samples(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	et := NewErrorTracker(2)
+	et.SetClock(clk)
+	et.Category("permission denied", os.ErrPermission)
+	et.Category("unused", os.ErrClosed)
+
+	for _, path := range []string{"/a", "/b", "/c"} {
+		clk.Advance(time.Second)
+		et.Add(&fs.PathError{Op: "open", Path: path, Err: os.ErrPermission})
+	}
+	et.Add(errors.New("boom"))
+
+	counts := et.Counts()
+	assert.Len(t, counts, 2)
+	assert.Equal(t, "permission denied", counts[0].Category)
+	assert.Equal(t, int64(3), counts[0].Count)
+	if assert.Len(t, counts[0].Samples, 2) {
+		assert.Equal(t, epoch.Add(2*time.Second), counts[0].Samples[0].Time)
+		assert.Equal(t, "open /c: permission denied", counts[0].Samples[1].Err.Error())
+	}
+	assert.Equal(t, "3 permission denied, e.g. open /c: permission denied; 1 other, e.g. boom", et.Summary())
+
+	et.SampleSize(1)
+	assert.Len(t, et.Counts()[0].Samples, 1)
+
+	et.Reset()
+	assert.Empty(t, et.Counts())
+	assert.Equal(t, int64(0), et.Total())
+	assert.Equal(t, "permission denied", et.Add(os.ErrPermission))
+}
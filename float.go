@@ -0,0 +1,34 @@
+package tracker
+
+// FloatCounter is a CounterOf float64
+type FloatCounter = CounterOf[float64]
+
+// FloatGauge is a GaugeOf float64
+type FloatGauge = GaugeOf[float64]
+
+// FloatSpeed is a SpeedOf float64
+type FloatSpeed = SpeedOf[float64]
+
+// NewFloatCounter returns an up/down counter of fractional values. It may
+// be decremented but never below zero.
+func NewFloatCounter() FloatCounter {
+	return NewCounterOf[float64]()
+}
+
+// NewFloatGauge returns a gauge of fractional values that may not go below
+// zero or overflow
+func NewFloatGauge() FloatGauge {
+	return NewGaugeOf[float64]()
+}
+
+// NewFloatSpeed returns a FloatSpeed measuring the value of c
+func NewFloatSpeed(c FloatCounter, n uint) FloatSpeed {
+	return NewCounterSpeedOf(c, n)
+}
+
+// NewFloatGaugeSpeed returns a FloatSpeed measuring the current value of g,
+// which also knows the total so it can report PercentRate and ETA. The
+// speed and the gauge share their paused state.
+func NewFloatGaugeSpeed(g FloatGauge, n uint) FloatSpeed {
+	return NewGaugeSpeedOf(g, n)
+}
@@ -0,0 +1,172 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/morrocker/tracker/trackertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_floatCounter_Current(t *testing.T) {
+	type want struct {
+		ret     float64
+		current float64
+		err     error
+	}
+	tests := []struct {
+		name    string
+		current float64
+		n       float64
+		want    want
+	}{
+		{
+			name:    "fractional add",
+			current: 1.5,
+			n:       0.25,
+			want:    want{ret: 1.75, current: 1.75},
+		},
+		{
+			name:    "decrement",
+			current: 1.5,
+			n:       -0.5,
+			want:    want{ret: 1, current: 1},
+		},
+		{
+			name:    "below zero",
+			current: 1.5,
+			n:       -2,
+			want:    want{current: 1.5, err: ErrNegative},
+		},
+		{
+			name:    "overflow",
+			current: math.MaxFloat64,
+			n:       math.MaxFloat64,
+			want:    want{current: math.MaxFloat64, err: ErrOverflow},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &counter[float64]{current: tt.current}
+			got, err := c.Current(tt.n)
+			assert.Equal(t, tt.want.ret, got)
+			assert.Equal(t, tt.want.current, c.RawValue())
+			if tt.want.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.want.err))
+			}
+		})
+	}
+}
+
+func Test_floatCounter_Concurrent(t *testing.T) {
+	c := NewFloatCounter()
+	var wg sync.WaitGroup
+	for x := 0; x < 8; x++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := 0; y < 1000; y++ {
+				c.Current(0.5)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, float64(4000), c.RawValue())
+}
+
+func Test_floatGauge_Values(t *testing.T) {
+	g := NewFloatGauge()
+	curr, tot := g.Values()
+	assert.Equal(t, "0.00", curr)
+	assert.Equal(t, "0.00", tot)
+
+	g.UnitsFunc(func(n float64) string {
+		return fmt.Sprintf("%.1f%%", n)
+	})
+	g.SetTotal(100)
+	g.Current(12.5)
+	curr, tot = g.Values()
+	assert.Equal(t, "12.5%", curr)
+	assert.Equal(t, "100.0%", tot)
+
+	_, err := g.Total(-200)
+	assert.True(t, errors.Is(err, ErrNegative))
+	g.Reset()
+	c, total := g.RawValues()
+	assert.Equal(t, float64(0), c)
+	assert.Equal(t, float64(0), total)
+}
+
+func Test_floatSpeed_AutoMeasure(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	g := NewFloatGauge()
+	g.SetTotal(10)
+	s := NewFloatGaugeSpeed(g, 5)
+	s.SetClock(clk)
+	s.UnitsFunc(func(n float64) string {
+		return fmt.Sprintf("%.2f/s", n)
+	})
+	s.StartAutoMeasure(2 * time.Second)
+	for _, n := range []float64{0.5, 1.5} {
+		g.Current(n)
+		clk.Advance(2 * time.Second)
+	}
+	s.StopAutoMeasure()
+
+	assert.Equal(t, 0.5, s.RawRate())
+	assert.Equal(t, "0.50/s", s.Rate())
+	assert.Equal(t, float64(5), s.PercentRate())
+	eta, ok := s.ETA()
+	assert.True(t, ok)
+	assert.Equal(t, 16*time.Second, eta)
+
+	s.Reset()
+	assert.Equal(t, float64(0), s.RawRate())
+}
+
+func Test_floatSpeed_Counter(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	c := NewFloatCounter()
+	s := NewFloatSpeed(c, 5)
+	s.SetClock(clk)
+	end := s.StartMeasure()
+	c.Current(0.75)
+	clk.Advance(500 * time.Millisecond)
+	end()
+	assert.Equal(t, 1.5, s.RawRate())
+	_, ok := s.ETA()
+	assert.False(t, ok)
+}
+
+func Test_floatSpeed_Decrease(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	g := NewFloatGauge()
+	g.SetTotal(10)
+	s := NewFloatGaugeSpeed(g, 5)
+	s.SetClock(clk)
+	s.ResetPolicy(ResetRollback)
+	g.Current(2)
+	end := s.StartMeasure()
+	g.Current(1)
+	clk.Advance(time.Second)
+	end()
+	end = s.StartMeasure()
+	g.Current(-2.5)
+	clk.Advance(time.Second)
+	end()
+
+	assert.Equal(t, uint64(1), s.Resets())
+	assert.Equal(t, 2.5, s.RollbackRate())
+	assert.Equal(t, []float64{1}, s.Samples())
+	assert.Equal(t, float64(1), s.MinRate())
+	assert.Equal(t, float64(1), s.MaxRate())
+
+	g.Pause()
+	assert.True(t, s.Paused())
+}
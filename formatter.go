@@ -0,0 +1,176 @@
+package tracker
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Unit is a kind of quantity, used to pick a formatter from the registry
+type Unit int
+
+const (
+	// Items renders plain numbers, with two decimals for floats
+	Items Unit = iota
+	// Bytes renders binary multiples such as 1.5 MiB
+	Bytes
+	// Duration renders integers as nanoseconds and floats as seconds, in
+	// the style of time.Duration
+	Duration
+)
+
+// Formatter renders a raw value of T for display. Formatters are plain
+// functions, so any of them can be passed to UnitsFunc, and they can be
+// chained to decorate the output.
+type Formatter[T Number] func(T) string
+
+// Prefix returns a Formatter that puts p before the output of f
+func (f Formatter[T]) Prefix(p string) Formatter[T] {
+	return func(n T) string {
+		return p + f(n)
+	}
+}
+
+// Suffix returns a Formatter that puts s after the output of f
+func (f Formatter[T]) Suffix(s string) Formatter[T] {
+	return func(n T) string {
+		return f(n) + s
+	}
+}
+
+// Then returns a Formatter that passes the output of f through g
+func (f Formatter[T]) Then(g func(string) string) Formatter[T] {
+	return func(n T) string {
+		return g(f(n))
+	}
+}
+
+// defaults holds the package wide func(T) string for each Number type,
+// keyed by the zero value of the type
+var defaults sync.Map
+
+// registry holds formatters registered for a Unit, keyed by unitKey
+var registry sync.Map
+
+type unitKey struct {
+	unit Unit
+	zero any
+}
+
+// RegisterUnits replaces the formatter used to render T as u. A nil f
+// restores the built in one.
+func RegisterUnits[T Number](u Unit, f func(T) string) {
+	var zero T
+	key := unitKey{unit: u, zero: any(zero)}
+	if f == nil {
+		registry.Delete(key)
+		return
+	}
+	registry.Store(key, f)
+}
+
+// UnitsFor returns a Formatter rendering T as u with the formatter
+// registered at the time of each call
+func UnitsFor[T Number](u Unit) Formatter[T] {
+	return func(n T) string {
+		var zero T
+		if f, ok := registry.Load(unitKey{unit: u, zero: any(zero)}); ok {
+			return f.(func(T) string)(n)
+		}
+		return builtinUnits(u, n)
+	}
+}
+
+// SetDefaultUnits sets the formatter used by trackers of T that have no
+// UnitsFunc of their own. A nil f goes back to rendering Items. It is safe
+// to call while trackers are being rendered.
+func SetDefaultUnits[T Number](f func(T) string) {
+	var zero T
+	if f == nil {
+		defaults.Delete(any(zero))
+		return
+	}
+	defaults.Store(any(zero), f)
+}
+
+// DefaultUnits returns a Formatter that renders with the default for T in
+// effect at the time of each call, so per-tracker chains such as
+// DefaultUnits[int64]().Suffix("/s") follow later SetDefaultUnits calls
+func DefaultUnits[T Number]() Formatter[T] {
+	return func(n T) string {
+		var zero T
+		if f, ok := defaults.Load(any(zero)); ok {
+			return f.(func(T) string)(n)
+		}
+		return UnitsFor[T](Items)(n)
+	}
+}
+
+// atomicUnits is a tracker's UnitsFunc, swappable while it is being read
+type atomicUnits[T Number] struct {
+	f atomic.Pointer[func(T) string]
+}
+
+func (u *atomicUnits[T]) set(f func(T) string) {
+	if f == nil {
+		u.f.Store(nil)
+		return
+	}
+	u.f.Store(&f)
+}
+
+func (u *atomicUnits[T]) get() func(T) string {
+	if f := u.f.Load(); f != nil {
+		return *f
+	}
+	return nil
+}
+
+// format renders n with the tracker's own formatter, falling back to the
+// package default for T
+func (u *atomicUnits[T]) format(n T) string {
+	if f := u.get(); f != nil {
+		return f(n)
+	}
+	return DefaultUnits[T]()(n)
+}
+
+func builtinUnits[T Number](u Unit, n T) string {
+	_, float := any(n).(float64)
+	if _, ok := any(n).(float32); ok {
+		float = true
+	}
+	switch u {
+	case Bytes:
+		return formatBytes(float64(n))
+	case Duration:
+		if float {
+			return time.Duration(float64(n) * float64(time.Second)).String()
+		}
+		return time.Duration(n).String()
+	}
+	if float {
+		return strconv.FormatFloat(float64(n), 'f', 2, 64)
+	}
+	if _, ok := any(n).(uint64); ok {
+		return strconv.FormatUint(uint64(n), 10)
+	}
+	return strconv.FormatInt(int64(n), 10)
+}
+
+func formatBytes(n float64) string {
+	const prefixes = "KMGTPE"
+	abs := math.Abs(n)
+	if abs < 1024 {
+		return strconv.FormatFloat(n, 'f', -1, 64) + " B"
+	}
+	exp := 0
+	for abs >= 1024*1024 && exp < len(prefixes)-1 {
+		abs /= 1024
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", math.Copysign(abs/1024, n), prefixes[exp])
+}
@@ -0,0 +1,139 @@
+package tracker
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatter_Chain(t *testing.T) {
+	base := Formatter[int64](func(n int64) string {
+		return fmt.Sprintf("%d", n)
+	})
+	tests := []struct {
+		name string
+		f    Formatter[int64]
+		want string
+	}{
+		{
+			name: "prefix",
+			f:    base.Prefix("~"),
+			want: "~42",
+		},
+		{
+			name: "suffix",
+			f:    base.Suffix(" B/s"),
+			want: "42 B/s",
+		},
+		{
+			name: "chained",
+			f:    base.Prefix("[").Suffix("]").Then(strings.ToUpper).Suffix(" items"),
+			want: "[42] items",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.f(42))
+		})
+	}
+}
+
+func TestSetDefaultUnits(t *testing.T) {
+	defer SetDefaultUnits[int64](nil)
+	c := NewCounter()
+	c.Current(7)
+	f := NewFloatCounter()
+	f.Current(1.5)
+	s := NewSpeed(new(int64), 5)
+	s.UnitsFunc(DefaultUnits[int64]().Suffix("/s"))
+
+	SetDefaultUnits(func(n int64) string {
+		return fmt.Sprintf("%d items", n)
+	})
+	assert.Equal(t, "7 items", c.Value())
+	assert.Equal(t, "0 items/s", s.Rate())
+	assert.Equal(t, "1.50", f.Value())
+
+	c.UnitsFunc(func(n int64) string {
+		return fmt.Sprintf("#%d", n)
+	})
+	assert.Equal(t, "#7", c.Value())
+	c.UnitsFunc(nil)
+	assert.Equal(t, "7 items", c.Value())
+
+	SetDefaultUnits[int64](nil)
+	assert.Equal(t, "7", c.Value())
+}
+
+func Test_atomicUnits_Swap(t *testing.T) {
+	g := NewGauge()
+	g.SetTotal(10)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for x := 0; x < 1000; x++ {
+			g.UnitsFunc(Formatter[int64](func(n int64) string {
+				return fmt.Sprintf("%d", n)
+			}).Suffix(fmt.Sprintf(" v%d", x)))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for x := 0; x < 1000; x++ {
+			g.Values()
+		}
+	}()
+	wg.Wait()
+	_, tot := g.Values()
+	assert.Equal(t, "10 v999", tot)
+}
+
+func TestUnitsFor(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "items int64", got: UnitsFor[int64](Items)(1234), want: "1234"},
+		{name: "items uint64", got: UnitsFor[uint64](Items)(1 << 63), want: "9223372036854775808"},
+		{name: "items float64", got: UnitsFor[float64](Items)(0.125), want: "0.12"},
+		{name: "bytes small", got: UnitsFor[int64](Bytes)(512), want: "512 B"},
+		{name: "bytes kibi", got: UnitsFor[int64](Bytes)(1536), want: "1.5 KiB"},
+		{name: "bytes mebi", got: UnitsFor[uint32](Bytes)(3 << 20), want: "3.0 MiB"},
+		{name: "bytes negative", got: UnitsFor[int64](Bytes)(-2048), want: "-2.0 KiB"},
+		{name: "duration int64", got: UnitsFor[int64](Duration)(int64(1500 * time.Millisecond)), want: "1.5s"},
+		{name: "duration float64", got: UnitsFor[float64](Duration)(90), want: "1m30s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.got)
+		})
+	}
+}
+
+func TestRegisterUnits(t *testing.T) {
+	defer RegisterUnits[int64](Bytes, nil)
+	bytes := UnitsFor[int64](Bytes)
+	RegisterUnits(Bytes, func(n int64) string {
+		return fmt.Sprintf("%d bytes", n)
+	})
+	assert.Equal(t, "2048 bytes", bytes(2048))
+	assert.Equal(t, "2.0 KiB", UnitsFor[uint64](Bytes)(2048))
+
+	RegisterUnits[int64](Bytes, nil)
+	assert.Equal(t, "2.0 KiB", bytes(2048))
+}
+
+func TestDefaultRendering(t *testing.T) {
+	var n int64 = 10
+	s := NewSpeed(&n, 5)
+	assert.NotPanics(t, func() { s.Rate() })
+	assert.Equal(t, "0", s.Rate())
+	assert.Equal(t, "0", NewShardedCounter(2).Value())
+	assert.Equal(t, "0.00", NewFloatSpeed(NewFloatCounter(), 5).Rate())
+}
@@ -2,67 +2,446 @@ package tracker
 
 import (
 	"sync/atomic"
+	"time"
 )
 
-type Gauge interface {
-	SetCurrent(n int64)
-	Current(n int64) (int64, error)
-	SetTotal(n int64)
-	Total(n int64) (int64, error)
-	RawValues() (int64, int64)
+// OverflowPolicy decides what a bounded Gauge does with an update that
+// would take current past total or below zero
+type OverflowPolicy int
+
+const (
+	// OverflowError rejects the update
+	OverflowError OverflowPolicy = iota
+	// OverflowClamp applies the update up to the nearest bound
+	OverflowClamp
+	// OverflowExtend raises total to fit current. Updates below zero are
+	// rejected.
+	OverflowExtend
+)
+
+// GaugeOf tracks a current value of type T against a total
+type GaugeOf[T Number] interface {
+	SetCurrent(n T)
+	Current(n T) (T, error)
+	SetTotal(n T)
+	Total(n T) (T, error)
+	RawValues() (T, T)
 	Values() (string, string)
-	UnitsFunc(func(int64) string)
+	UnitsFunc(func(T) string)
 	Reset()
-	Pointers() (*int64, *int64)
+	Pointers() (*T, *T)
+	SetClock(Clock)
+	Pause()
+	Resume()
+	Paused() bool
+	Start()
+	Finish()
+	Started() time.Time
+	Finished() time.Time
+	Elapsed() time.Duration
+	ActiveTime() time.Duration
+	AverageRate() T
+	Swap(n T) T
+	Cursor() Cursor[T]
 }
 
-type gauge struct {
-	current   int64
-	total     int64
-	unitsFunc func(int64) string
+// Gauge is a GaugeOf int64
+type Gauge = GaugeOf[int64]
+
+type gauge[T Number] struct {
+	current   T
+	total     T
+	padded    *paddedValues[T]
+	shared    *[2]T
+	bounded   bool
+	policy    OverflowPolicy
+	unitsFunc atomicUnits[T]
+	pause     pauser
+	started   atomic.Pointer[stamp]
+	finished  atomic.Pointer[stamp]
+	autoEnd   atomic.Bool
+	epoch     resetEpoch[T]
 }
 
+// NewGauge returns a gauge whose values may not go below zero or overflow,
+// but whose current value is free to exceed the total
 func NewGauge() Gauge {
-	newGauge := &gauge{
-	}
+	return NewGaugeOf[int64]()
+}
+
+// NewBoundedGauge returns a gauge that keeps current between zero and total,
+// handling updates that would leave that range according to p
+func NewBoundedGauge(p OverflowPolicy) Gauge {
+	return NewBoundedGaugeOf[int64](p)
+}
+
+// NewPaddedGauge returns an unbounded gauge whose current and total live on
+// separate cache lines, so hot writers of current do not slow down readers
+// of total. It costs a few cache lines of memory per gauge.
+func NewPaddedGauge() Gauge {
+	return NewPaddedGaugeOf[int64]()
+}
+
+// NewGaugeOf returns an unbounded gauge of T
+func NewGaugeOf[T Number]() GaugeOf[T] {
+	newGauge := &gauge[T]{}
 	return newGauge
 }
 
-func (g *gauge) SetCurrent(n int64) {
-	atomic.CompareAndSwapInt64(&g.current, g.current, n)
+// NewPaddedGaugeOf returns an unbounded gauge of T with the padded layout
+func NewPaddedGaugeOf[T Number]() GaugeOf[T] {
+	newGauge := &gauge[T]{
+		padded: &paddedValues[T]{},
+	}
+	return newGauge
 }
-func (g *gauge) Current(n int64) (int64, error) {
-	atomic.AddInt64(&g.current, n)
-	return g.current, nil
+
+// NewBoundedGaugeOf returns a bounded gauge of T
+func NewBoundedGaugeOf[T Number](p OverflowPolicy) GaugeOf[T] {
+	newGauge := &gauge[T]{
+		bounded: true,
+		policy:  p,
+	}
+	return newGauge
 }
 
-func (g *gauge) SetTotal(n int64) {
-	atomic.CompareAndSwapInt64(&g.total, g.total, n)
+// SetCurrent sets the current value to n. Values below zero are ignored,
+// and bounded gauges apply their overflow policy to values past the total,
+// ignoring them under OverflowError.
+func (g *gauge[T]) SetCurrent(n T) {
+	current, total := g.Pointers()
+	n, err := g.bound(n, load(total))
+	if err != nil {
+		return
+	}
+	if g.bounded && g.policy == OverflowExtend {
+		raise(total, n)
+	}
+	store(current, n)
+	g.progressed(n, load(total))
 }
-func (g *gauge) Total(n int64) (int64, error) {
-	atomic.AddInt64(&g.total, n)
-	return g.total, nil
+
+// Current adds n to the current value and returns the result. Rejected
+// updates leave the gauge unchanged and return an error wrapping
+// ErrNegative, ErrExceedsTotal or ErrOverflow.
+func (g *gauge[T]) Current(n T) (T, error) {
+	current, total := g.Pointers()
+	for {
+		cur := load(current)
+		next, err := g.next(cur, n, load(total))
+		if err != nil {
+			return 0, deltaError(cur, n, err)
+		}
+		if !cas(current, cur, next) {
+			continue
+		}
+		if g.bounded && g.policy == OverflowExtend {
+			raise(total, next)
+		}
+		g.progressed(next, load(total))
+		return next, nil
+	}
 }
 
-func (g *gauge) RawValues() (int64, int64) {
-	return g.current, g.total
+// SetTotal sets the total to n. Values below zero are ignored, as are
+// values below current on a bounded gauge using OverflowError. Other
+// bounded gauges raise such values to current.
+func (g *gauge[T]) SetTotal(n T) {
+	current, total := g.Pointers()
+	if n < 0 {
+		return
+	}
+	if cur := load(current); g.bounded && n < cur {
+		if g.policy == OverflowError {
+			return
+		}
+		n = cur
+	}
+	store(total, n)
+	g.progressed(load(current), n)
 }
-func (g *gauge) Values() (string, string) {
-	if g.unitsFunc == nil {
-		return "unitsFunction not set", "unitsFunction not set"
+
+// Total adds n to the total and returns the result. Totals may not go below
+// zero, nor below current on a bounded gauge using OverflowError.
+func (g *gauge[T]) Total(n T) (T, error) {
+	current, totalp := g.Pointers()
+	for {
+		total := load(totalp)
+		next, err := add(total, n)
+		if err == nil && g.bounded {
+			if cur := load(current); next < cur {
+				if g.policy == OverflowError {
+					err = ErrExceedsTotal
+				}
+				next = cur
+			}
+		}
+		if err != nil {
+			return 0, deltaError(total, n, err)
+		}
+		if cas(totalp, total, next) {
+			g.progressed(load(current), next)
+			return next, nil
+		}
 	}
-	return g.unitsFunc(g.current), g.unitsFunc(g.total)
 }
 
-func (g *gauge) UnitsFunc(f func(int64) string) {
-	g.unitsFunc = f
+func (g *gauge[T]) RawValues() (T, T) {
+	current, total := g.Pointers()
+	return load(current), load(total)
 }
 
-func (g *gauge) Reset(){
-	atomic.CompareAndSwapInt64(&g.current, g.current, 0)
-	atomic.CompareAndSwapInt64(&g.total, g.total, 0)
+func (g *gauge[T]) Values() (string, string) {
+	current, total := g.RawValues()
+	return g.unitsFunc.format(current), g.unitsFunc.format(total)
 }
 
-func (g *gauge) Pointers() (*int64, *int64) {
+func (g *gauge[T]) UnitsFunc(f func(T) string) {
+	g.unitsFunc.set(f)
+}
+
+// Reset zeroes the values and clears the start and finish times
+func (g *gauge[T]) Reset() {
+	_, total := g.Pointers()
+	g.Swap(0)
+	store(total, 0)
+	g.started.Store(nil)
+	g.finished.Store(nil)
+	g.autoEnd.Store(false)
+}
+
+// Swap sets current to n and returns the old value, for collecting and
+// resetting in one step. Cursors do not see it as a change. Values below
+// zero are treated as zero, and bounded gauges apply their overflow policy
+// to values past the total. Under OverflowError those leave the gauge
+// unchanged and Swap returns the current value.
+func (g *gauge[T]) Swap(n T) T {
+	current, total := g.Pointers()
+	if n < 0 {
+		n = 0
+	}
+	n, err := g.bound(n, load(total))
+	if err != nil {
+		return load(current)
+	}
+	if g.bounded && g.policy == OverflowExtend {
+		raise(total, n)
+	}
+	old := g.epoch.swap(n, func(n T) T {
+		return exchange(current, n)
+	})
+	g.progressed(n, load(total))
+	return old
+}
+
+// Cursor returns a Cursor over the current value, starting from now.
+// Reset and Swap do not disturb it; other updates, including decreases,
+// show up as changes.
+func (g *gauge[T]) Cursor() Cursor[T] {
+	return newCursor(func() T {
+		return g.epoch.read(func() T {
+			current, _ := g.RawValues()
+			return current
+		})
+	})
+}
+
+// Pointers returns the addresses of current and total. Floats must be
+// accessed through their bit patterns with the atomic package.
+func (g *gauge[T]) Pointers() (*T, *T) {
+	if g.padded != nil {
+		return &g.padded.current, &g.padded.total
+	}
+	if g.shared != nil {
+		return &g.shared[0], &g.shared[1]
+	}
 	return &g.current, &g.total
 }
+
+// SetClock sets the clock used for the start and finish times and to time
+// pauses
+func (g *gauge[T]) SetClock(c Clock) {
+	g.pause.setClock(c)
+}
+
+// Pause marks the gauge as not making progress until Resume. Speeds made
+// with NewGaugeSpeed leave the paused time out of their rates.
+func (g *gauge[T]) Pause() {
+	g.pause.pause()
+}
+
+func (g *gauge[T]) Resume() {
+	g.pause.resume()
+}
+
+func (g *gauge[T]) Paused() bool {
+	return g.pause.isPaused()
+}
+
+func (g *gauge[T]) pauses() *pauser {
+	return &g.pause
+}
+
+func (g *gauge[T]) resets() *resetEpoch[T] {
+	return &g.epoch
+}
+
+// Start records the start time, unless the gauge already has one. Gauges
+// also start on their first update to a non zero current value.
+func (g *gauge[T]) Start() {
+	g.mark(&g.started)
+}
+
+// Finish records the finish time, unless the gauge already has one.
+// Gauges also finish when current reaches a non zero total, but unlike an
+// explicit Finish that is undone if the total grows past current again.
+func (g *gauge[T]) Finish() {
+	g.Start()
+	g.autoEnd.Store(false)
+	g.mark(&g.finished)
+}
+
+// stamp is a point in a gauge's lifetime, on the wall clock and on the
+// clock that stands still while the gauge is paused
+type stamp struct {
+	wall   time.Time
+	active time.Time
+}
+
+func (g *gauge[T]) now() *stamp {
+	wall := g.pause.getClock()
+	return &stamp{
+		wall:   wall.Now(),
+		active: activeClock{wall, &g.pause}.Now(),
+	}
+}
+
+func (g *gauge[T]) mark(t *atomic.Pointer[stamp]) bool {
+	if t.Load() != nil {
+		return false
+	}
+	return t.CompareAndSwap(nil, g.now())
+}
+
+// progressed starts or finishes the gauge after an update, and clears a
+// finish it set itself once current falls behind the total again
+func (g *gauge[T]) progressed(current, total T) {
+	if current != 0 && g.started.Load() == nil {
+		g.Start()
+	}
+	switch {
+	case total > 0 && current >= total:
+		if g.finished.Load() == nil {
+			g.Start()
+			if g.mark(&g.finished) {
+				g.autoEnd.Store(true)
+			}
+		}
+	case g.autoEnd.Load():
+		if end := g.finished.Load(); end != nil && g.autoEnd.CompareAndSwap(true, false) {
+			g.finished.CompareAndSwap(end, nil)
+		}
+	}
+}
+
+// Started returns the start time, or the zero time
+func (g *gauge[T]) Started() time.Time {
+	if t := g.started.Load(); t != nil {
+		return t.wall
+	}
+	return time.Time{}
+}
+
+// Finished returns the finish time, or the zero time
+func (g *gauge[T]) Finished() time.Time {
+	if t := g.finished.Load(); t != nil {
+		return t.wall
+	}
+	return time.Time{}
+}
+
+// Elapsed returns the wall time from start to finish, or to now for gauges
+// that have not finished. It includes time spent paused.
+func (g *gauge[T]) Elapsed() time.Duration {
+	start := g.started.Load()
+	if start == nil {
+		return 0
+	}
+	end := g.finished.Load()
+	if end == nil {
+		end = g.now()
+	}
+	return end.wall.Sub(start.wall)
+}
+
+// ActiveTime is like Elapsed but leaves out the time spent paused
+func (g *gauge[T]) ActiveTime() time.Duration {
+	start := g.started.Load()
+	if start == nil {
+		return 0
+	}
+	end := g.finished.Load()
+	if end == nil {
+		end = g.now()
+	}
+	return end.active.Sub(start.active)
+}
+
+// AverageRate returns the current value per second of Elapsed time, paused
+// time included
+func (g *gauge[T]) AverageRate() T {
+	elapsed := g.Elapsed()
+	if elapsed <= 0 {
+		return 0
+	}
+	current, _ := g.RawValues()
+	return T(float64(current) / elapsed.Seconds())
+}
+
+// next works out the current value after adding n, applying the overflow
+// policy on bounded gauges
+func (g *gauge[T]) next(cur, n, total T) (T, error) {
+	next, err := add(cur, n)
+	if !g.bounded {
+		return next, err
+	}
+	clamp := g.policy == OverflowClamp
+	switch {
+	case err == ErrOverflow && clamp:
+		return total, nil
+	case err == ErrNegative && clamp:
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+	return g.bound(next, total)
+}
+
+// bound checks a new current value against zero and, on bounded gauges,
+// applies the overflow policy to it
+func (g *gauge[T]) bound(next, total T) (T, error) {
+	clamp := g.bounded && g.policy == OverflowClamp
+	switch {
+	case next < 0 && clamp:
+		return 0, nil
+	case next < 0:
+		return 0, ErrNegative
+	case !g.bounded:
+		return next, nil
+	case next > total && clamp:
+		return total, nil
+	case next > total && g.policy == OverflowError:
+		return 0, ErrExceedsTotal
+	}
+	return next, nil
+}
+
+// paddedValues keeps current and total a cache line away from each other
+// and from whatever is allocated next to them
+type paddedValues[T Number] struct {
+	_       [cacheLine]byte
+	current T
+	_       [cacheLine]byte
+	total   T
+	_       [cacheLine]byte
+}
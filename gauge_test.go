@@ -1,10 +1,15 @@
 package tracker
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"testing"
+	"time"
+	"unsafe"
 
+	"github.com/morrocker/tracker/trackertest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,7 +27,7 @@ func TestNewGauge(t *testing.T) {
 			args: args{
 				name: "object A",
 			},
-			want: &gauge{
+			want: &gauge[int64]{
 				current: 0,
 				total:   0,
 			},
@@ -64,7 +69,7 @@ func Test_gauge_SetCurrent(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := &gauge{
+			g := &gauge[int64]{
 				current: tt.fields.current,
 			}
 			g.SetCurrent(tt.args.n)
@@ -137,7 +142,7 @@ func Test_gauge_Current(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := &gauge{
+			g := &gauge[int64]{
 				current: tt.fields.current,
 			}
 			got, err := g.Current(tt.args.n)
@@ -179,7 +184,7 @@ func Test_gauge_SetTotal(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := &gauge{
+			g := &gauge[int64]{
 				total: tt.fields.total,
 			}
 			g.SetTotal(tt.args.n)
@@ -241,7 +246,7 @@ func Test_gauge_Total(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := &gauge{
+			g := &gauge[int64]{
 				total: tt.fields.total,
 			}
 			got, err := g.Total(tt.args.n)
@@ -288,7 +293,7 @@ func Test_gauge_RawValues(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := &gauge{
+			g := &gauge[int64]{
 				current: tt.fields.current,
 				total:   tt.fields.total,
 			}
@@ -338,18 +343,18 @@ func Test_gauge_Value(t *testing.T) {
 				total:   100,
 			},
 			want: want{
-				current: "unitsFunction not set",
-				total:   "unitsFunction not set",
+				current: "55",
+				total:   "100",
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := &gauge{
-				current:   tt.fields.current,
-				total:     tt.fields.total,
-				unitsFunc: tt.fields.unitsFunc,
+			g := &gauge[int64]{
+				current: tt.fields.current,
+				total:   tt.fields.total,
 			}
+			g.UnitsFunc(tt.fields.unitsFunc)
 			curr, tot := g.Values()
 			assert.Equal(t, tt.want.current, curr)
 			assert.Equal(t, tt.want.total, tot)
@@ -421,12 +426,12 @@ func Test_gauge_UnitsFunc(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := &gauge{
+			g := &gauge[int64]{
 				total:   tt.fields.total,
 				current: tt.fields.current,
 			}
 			g.UnitsFunc(tt.args.f)
-			ptr1 := reflect.ValueOf(g.unitsFunc).Pointer()
+			ptr1 := reflect.ValueOf(g.unitsFunc.get()).Pointer()
 			ptr2 := reflect.ValueOf(tt.want.f).Pointer()
 			curr, tot := g.Values()
 			if tt.want.err {
@@ -439,3 +444,458 @@ func Test_gauge_UnitsFunc(t *testing.T) {
 		})
 	}
 }
+
+func Test_gauge_Bounded(t *testing.T) {
+	type want struct {
+		ret   int64
+		curr  int64
+		total int64
+		err   error
+	}
+	tests := []struct {
+		name    string
+		bounded bool
+		policy  OverflowPolicy
+		current int64
+		n       int64
+		set     bool
+		want    want
+	}{
+		{
+			name:    "unbounded past total",
+			current: 90,
+			n:       20,
+			want:    want{ret: 110, curr: 110, total: 100},
+		},
+		{
+			name:    "unbounded overflow",
+			current: math.MaxInt64 - 5,
+			n:       10,
+			want:    want{curr: math.MaxInt64 - 5, total: 100, err: ErrOverflow},
+		},
+		{
+			name:    "error past total",
+			bounded: true,
+			policy:  OverflowError,
+			current: 90,
+			n:       20,
+			want:    want{curr: 90, total: 100, err: ErrExceedsTotal},
+		},
+		{
+			name:    "error below zero",
+			bounded: true,
+			policy:  OverflowError,
+			current: 10,
+			n:       -20,
+			want:    want{curr: 10, total: 100, err: ErrNegative},
+		},
+		{
+			name:    "error within bounds",
+			bounded: true,
+			policy:  OverflowError,
+			current: 90,
+			n:       10,
+			want:    want{ret: 100, curr: 100, total: 100},
+		},
+		{
+			name:    "clamp past total",
+			bounded: true,
+			policy:  OverflowClamp,
+			current: 90,
+			n:       20,
+			want:    want{ret: 100, curr: 100, total: 100},
+		},
+		{
+			name:    "clamp below zero",
+			bounded: true,
+			policy:  OverflowClamp,
+			current: 10,
+			n:       -20,
+			want:    want{ret: 0, curr: 0, total: 100},
+		},
+		{
+			name:    "clamp overflow",
+			bounded: true,
+			policy:  OverflowClamp,
+			current: math.MaxInt64 - 5,
+			n:       10,
+			want:    want{ret: 100, curr: 100, total: 100},
+		},
+		{
+			name:    "extend past total",
+			bounded: true,
+			policy:  OverflowExtend,
+			current: 90,
+			n:       20,
+			want:    want{ret: 110, curr: 110, total: 110},
+		},
+		{
+			name:    "extend below zero",
+			bounded: true,
+			policy:  OverflowExtend,
+			current: 10,
+			n:       -20,
+			want:    want{curr: 10, total: 100, err: ErrNegative},
+		},
+		{
+			name:    "unbounded set below zero",
+			current: 10,
+			n:       -5,
+			set:     true,
+			want:    want{curr: 10, total: 100},
+		},
+		{
+			name:    "error set past total",
+			bounded: true,
+			policy:  OverflowError,
+			current: 10,
+			n:       500,
+			set:     true,
+			want:    want{curr: 10, total: 100},
+		},
+		{
+			name:    "error set below zero",
+			bounded: true,
+			policy:  OverflowError,
+			current: 10,
+			n:       -5,
+			set:     true,
+			want:    want{curr: 10, total: 100},
+		},
+		{
+			name:    "error set within bounds",
+			bounded: true,
+			policy:  OverflowError,
+			current: 10,
+			n:       100,
+			set:     true,
+			want:    want{curr: 100, total: 100},
+		},
+		{
+			name:    "clamp set past total",
+			bounded: true,
+			policy:  OverflowClamp,
+			current: 10,
+			n:       500,
+			set:     true,
+			want:    want{curr: 100, total: 100},
+		},
+		{
+			name:    "clamp set below zero",
+			bounded: true,
+			policy:  OverflowClamp,
+			current: 10,
+			n:       -5,
+			set:     true,
+			want:    want{curr: 0, total: 100},
+		},
+		{
+			name:    "extend set past total",
+			bounded: true,
+			policy:  OverflowExtend,
+			current: 10,
+			n:       500,
+			set:     true,
+			want:    want{curr: 500, total: 500},
+		},
+		{
+			name:    "extend set below zero",
+			bounded: true,
+			policy:  OverflowExtend,
+			current: 10,
+			n:       -5,
+			set:     true,
+			want:    want{curr: 10, total: 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &gauge[int64]{
+				current: tt.current,
+				total:   100,
+				bounded: tt.bounded,
+				policy:  tt.policy,
+			}
+			if tt.set {
+				g.SetCurrent(tt.n)
+				assert.Equal(t, tt.want.curr, g.current)
+				assert.Equal(t, tt.want.total, g.total)
+				return
+			}
+			got, err := g.Current(tt.n)
+			assert.Equal(t, tt.want.ret, got)
+			assert.Equal(t, tt.want.curr, g.current)
+			assert.Equal(t, tt.want.total, g.total)
+			if tt.want.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.want.err), "got %v", err)
+			}
+		})
+	}
+}
+
+func Test_gauge_BoundedTotal(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		n      int64
+		ret    int64
+		total  int64
+		err    error
+	}{
+		{
+			name:   "error below current",
+			policy: OverflowError,
+			n:      -60,
+			total:  100,
+			err:    ErrExceedsTotal,
+		},
+		{
+			name:   "clamp below current",
+			policy: OverflowClamp,
+			n:      -60,
+			ret:    50,
+			total:  50,
+		},
+		{
+			name:   "overflow",
+			policy: OverflowExtend,
+			n:      math.MaxInt64,
+			total:  100,
+			err:    ErrOverflow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewBoundedGauge(tt.policy)
+			g.SetTotal(100)
+			g.SetCurrent(50)
+			got, err := g.Total(tt.n)
+			assert.Equal(t, tt.ret, got)
+			_, total := g.RawValues()
+			assert.Equal(t, tt.total, total)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.err), "got %v", err)
+			}
+		})
+	}
+}
+
+func Test_gauge_Padded(t *testing.T) {
+	g := NewPaddedGauge()
+	g.SetTotal(100)
+	g.Current(40)
+	curr, tot := g.RawValues()
+	assert.Equal(t, int64(40), curr)
+	assert.Equal(t, int64(100), tot)
+
+	pc, pt := g.Pointers()
+	dist := uintptr(unsafe.Pointer(pt)) - uintptr(unsafe.Pointer(pc))
+	assert.True(t, dist >= cacheLine, "current and total %d bytes apart", dist)
+	assert.Equal(t, int64(40), *pc)
+
+	g.Reset()
+	curr, tot = g.RawValues()
+	assert.Equal(t, int64(0), curr)
+	assert.Equal(t, int64(0), tot)
+}
+
+func Test_gauge_Pause(t *testing.T) {
+	g := NewGauge()
+	assert.False(t, g.Paused())
+	g.Pause()
+	assert.True(t, g.Paused())
+	_, err := g.Current(5)
+	assert.NoError(t, err)
+	g.Resume()
+	assert.False(t, g.Paused())
+}
+
+func Test_gauge_Lifetime(t *testing.T) {
+	tests := []struct {
+		name     string
+		steps    func(Gauge, *trackertest.Clock)
+		started  time.Duration
+		finished time.Duration
+		elapsed  time.Duration
+		rate     int64
+	}{
+		{
+			name: "starts on first update and finishes at total",
+			steps: func(g Gauge, clk *trackertest.Clock) {
+				g.SetTotal(100)
+				clk.Advance(time.Second)
+				g.Current(40)
+				clk.Advance(time.Second)
+				g.Current(60)
+				clk.Advance(time.Second)
+			},
+			started:  time.Second,
+			finished: 2 * time.Second,
+			elapsed:  time.Second,
+			rate:     100,
+		},
+		{
+			name: "running",
+			steps: func(g Gauge, clk *trackertest.Clock) {
+				g.SetCurrent(30)
+				clk.Advance(3 * time.Second)
+			},
+			started: 0,
+			elapsed: 3 * time.Second,
+			rate:    10,
+		},
+		{
+			name: "explicit start and finish",
+			steps: func(g Gauge, clk *trackertest.Clock) {
+				g.Start()
+				clk.Advance(2 * time.Second)
+				g.Current(10)
+				g.Finish()
+				clk.Advance(time.Second)
+				g.Finish()
+			},
+			started:  0,
+			finished: 2 * time.Second,
+			elapsed:  2 * time.Second,
+			rate:     5,
+		},
+		{
+			name: "total grows after reaching it",
+			steps: func(g Gauge, clk *trackertest.Clock) {
+				g.SetTotal(50)
+				g.SetCurrent(50)
+				clk.Advance(time.Second)
+				g.Total(50)
+				clk.Advance(time.Second)
+				g.Current(50)
+				clk.Advance(time.Second)
+			},
+			started:  0,
+			finished: 2 * time.Second,
+			elapsed:  2 * time.Second,
+			rate:     50,
+		},
+		{
+			name: "total grows after explicit finish",
+			steps: func(g Gauge, clk *trackertest.Clock) {
+				g.SetCurrent(10)
+				clk.Advance(time.Second)
+				g.Finish()
+				clk.Advance(time.Second)
+				g.SetTotal(20)
+			},
+			started:  0,
+			finished: time.Second,
+			elapsed:  time.Second,
+			rate:     10,
+		},
+		{
+			name: "total raised past current",
+			steps: func(g Gauge, clk *trackertest.Clock) {
+				g.SetCurrent(10)
+				clk.Advance(time.Second)
+				g.Total(20)
+			},
+			started: 0,
+			elapsed: time.Second,
+			rate:    10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := trackertest.NewClock(epoch)
+			g := NewGauge()
+			g.SetClock(clk)
+			tt.steps(g, clk)
+			assert.Equal(t, epoch.Add(tt.started), g.Started())
+			if tt.finished > 0 {
+				assert.Equal(t, epoch.Add(tt.finished), g.Finished())
+			} else {
+				assert.True(t, g.Finished().IsZero())
+			}
+			assert.Equal(t, tt.elapsed, g.Elapsed())
+			assert.Equal(t, tt.rate, g.AverageRate())
+		})
+	}
+}
+
+func Test_gauge_LifetimeReset(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	g := NewGauge()
+	g.SetClock(clk)
+	assert.Equal(t, time.Duration(0), g.Elapsed())
+	assert.Equal(t, int64(0), g.AverageRate())
+	g.SetTotal(10)
+	g.SetCurrent(10)
+	clk.Advance(time.Second)
+	g.Reset()
+	assert.True(t, g.Started().IsZero())
+	assert.True(t, g.Finished().IsZero())
+	assert.Equal(t, time.Duration(0), g.Elapsed())
+
+	g.Current(5)
+	assert.Equal(t, epoch.Add(time.Second), g.Started())
+}
+
+func Test_gauge_BoundedSetTotal(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		n      int64
+		total  int64
+	}{
+		{
+			name:   "error below current",
+			policy: OverflowError,
+			n:      20,
+			total:  100,
+		},
+		{
+			name:   "clamp below current",
+			policy: OverflowClamp,
+			n:      20,
+			total:  50,
+		},
+		{
+			name:   "below zero",
+			policy: OverflowExtend,
+			n:      -1,
+			total:  100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewBoundedGauge(tt.policy)
+			g.SetTotal(100)
+			g.SetCurrent(50)
+			g.SetTotal(tt.n)
+			_, total := g.RawValues()
+			assert.Equal(t, tt.total, total)
+		})
+	}
+}
+
+func Test_gauge_ActiveTime(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	g := NewGauge()
+	g.SetClock(clk)
+	assert.Equal(t, time.Duration(0), g.ActiveTime())
+	g.SetTotal(100)
+	g.Current(10)
+	clk.Advance(time.Second)
+	g.Pause()
+	clk.Advance(2 * time.Second)
+	g.Resume()
+	clk.Advance(time.Second)
+	assert.Equal(t, 4*time.Second, g.Elapsed())
+	assert.Equal(t, 2*time.Second, g.ActiveTime())
+
+	g.Current(90)
+	clk.Advance(time.Second)
+	assert.Equal(t, 4*time.Second, g.Elapsed())
+	assert.Equal(t, 2*time.Second, g.ActiveTime())
+}
@@ -1,8 +1,11 @@
 module github.com/morrocker/tracker
 
-go 1.16
+go 1.19
+
+require github.com/stretchr/testify v1.7.0
 
 require (
-	github.com/morrocker/benchmark v0.0.0-20210605042952-9c82b225f734
-	github.com/stretchr/testify v1.7.0
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
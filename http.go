@@ -0,0 +1,47 @@
+package tracker
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+//go:embed dashboard.html
+var dashboard []byte
+
+// snapshotPath is where a handler serves the JSON snapshot, relative to
+// the path it is mounted on
+const snapshotPath = "snapshot.json"
+
+// NewHandler returns an http.Handler serving a live view of s. Requests for
+// snapshot.json get the current Readings as JSON and anything else gets a
+// self contained dashboard page that polls it. Mount it on a path ending in
+// a slash, e.g. with http.StripPrefix.
+func NewHandler(s Set) http.Handler {
+	return &handler{set: s}
+}
+
+type handler struct {
+	set Set
+}
+
+type snapshotJSON struct {
+	Trackers []Reading `json:"trackers"`
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	if strings.HasSuffix(r.URL.Path, "/"+snapshotPath) || r.URL.Path == snapshotPath {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(snapshotJSON{Trackers: h.set.Read()})
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(dashboard)
+}
@@ -0,0 +1,91 @@
+package tracker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	g := NewGauge()
+	g.SetTotal(200)
+	g.Current(50)
+	s := NewSet()
+	s.AddGauge("upload", g)
+	s.AddSpeed("upload", &fixedSpeed{rate: 10})
+	h := NewHandler(s)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		code        int
+		contentType string
+	}{
+		{
+			name:        "dashboard",
+			method:      http.MethodGet,
+			path:        "/",
+			code:        http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+		},
+		{
+			name:        "snapshot",
+			method:      http.MethodGet,
+			path:        "/jobs/snapshot.json",
+			code:        http.StatusOK,
+			contentType: "application/json",
+		},
+		{
+			name:   "post",
+			method: http.MethodPost,
+			path:   "/snapshot.json",
+			code:   http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			assert.Equal(t, tt.code, rec.Code)
+			if tt.contentType != "" {
+				assert.Equal(t, tt.contentType, rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestHandler_Snapshot(t *testing.T) {
+	g := NewGauge()
+	g.SetTotal(200)
+	g.Current(50)
+	s := NewSet()
+	s.AddGauge("upload", g)
+	s.AddSpeed("upload", &fixedSpeed{rate: 10})
+	s.AddCounter("files", NewCounter())
+
+	rec := httptest.NewRecorder()
+	NewHandler(s).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/snapshot.json", nil))
+	var got struct {
+		Trackers []Reading `json:"trackers"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, s.Read(), got.Trackers)
+	assert.Equal(t, 15*time.Second, got.Trackers[0].ETA)
+	assert.Equal(t, float64(25), got.Trackers[0].Percent)
+}
+
+func TestHandler_DashboardSelfContained(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(NewSet()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, "snapshot.json")
+	for _, external := range []string{"http://", "https://", "src=", "href="} {
+		assert.False(t, strings.Contains(body, external), "dashboard references %q", external)
+	}
+}
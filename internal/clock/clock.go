@@ -0,0 +1,96 @@
+// Package clock defines the time source used by tracker so that tests can
+// substitute a manually advanced clock for the wall clock.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time and runs functions periodically
+type Clock interface {
+	Now() time.Time
+	NewTicker(time.Duration, func(time.Time)) Ticker
+}
+
+// Sleeper is implemented by clocks that can block for a duration
+type Sleeper interface {
+	Sleep(time.Duration)
+}
+
+// Sleep blocks for d on c, using its Sleep method when c is a Sleeper and a
+// single tick of a ticker otherwise
+func Sleep(c Clock, d time.Duration) {
+	if s, ok := c.(Sleeper); ok {
+		s.Sleep(d)
+		return
+	}
+	if d <= 0 {
+		return
+	}
+	done := make(chan struct{})
+	var once sync.Once
+	t := c.NewTicker(d, func(time.Time) {
+		once.Do(func() { close(done) })
+	})
+	<-done
+	t.Stop()
+}
+
+// Ticker calls its function once per period until stopped
+type Ticker interface {
+	Reset(time.Duration)
+	Stop()
+}
+
+// Real is a Clock backed by the time package
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+func (Real) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// NewTicker calls f from a new goroutine every d
+func (Real) NewTicker(d time.Duration, f func(time.Time)) Ticker {
+	t := &realTicker{
+		t:       time.NewTicker(d),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go t.run(f)
+	return t
+}
+
+type realTicker struct {
+	t       *time.Ticker
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func (r *realTicker) run(f func(time.Time)) {
+	defer close(r.stopped)
+	for {
+		select {
+		case now := <-r.t.C:
+			f(now)
+		case <-r.done:
+			return
+		}
+	}
+}
+
+func (r *realTicker) Reset(d time.Duration) {
+	r.t.Reset(d)
+}
+
+// Stop waits for a call in progress to return, so it must not be called
+// from the ticker's own function
+func (r *realTicker) Stop() {
+	r.t.Stop()
+	close(r.done)
+	<-r.stopped
+}
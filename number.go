@@ -0,0 +1,115 @@
+package tracker
+
+import (
+	"math"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Number is the set of value types a Counter or Gauge can hold
+type Number interface {
+	int32 | int64 | uint32 | uint64 | float32 | float64
+}
+
+// load, store and cas give every Number type atomic access. Floats are
+// handled through their bit patterns.
+
+func load[T Number](addr *T) T {
+	switch p := any(addr).(type) {
+	case *int64:
+		return T(atomic.LoadInt64(p))
+	case *int32:
+		return T(atomic.LoadInt32(p))
+	case *uint64:
+		return T(atomic.LoadUint64(p))
+	case *uint32:
+		return T(atomic.LoadUint32(p))
+	case *float64:
+		return T(math.Float64frombits(atomic.LoadUint64((*uint64)(unsafe.Pointer(p)))))
+	case *float32:
+		return T(math.Float32frombits(atomic.LoadUint32((*uint32)(unsafe.Pointer(p)))))
+	}
+	panic("tracker: unsupported number type")
+}
+
+func store[T Number](addr *T, n T) {
+	switch p := any(addr).(type) {
+	case *int64:
+		atomic.StoreInt64(p, int64(n))
+	case *int32:
+		atomic.StoreInt32(p, int32(n))
+	case *uint64:
+		atomic.StoreUint64(p, uint64(n))
+	case *uint32:
+		atomic.StoreUint32(p, uint32(n))
+	case *float64:
+		atomic.StoreUint64((*uint64)(unsafe.Pointer(p)), math.Float64bits(float64(n)))
+	case *float32:
+		atomic.StoreUint32((*uint32)(unsafe.Pointer(p)), math.Float32bits(float32(n)))
+	default:
+		panic("tracker: unsupported number type")
+	}
+}
+
+func cas[T Number](addr *T, old, n T) bool {
+	switch p := any(addr).(type) {
+	case *int64:
+		return atomic.CompareAndSwapInt64(p, int64(old), int64(n))
+	case *int32:
+		return atomic.CompareAndSwapInt32(p, int32(old), int32(n))
+	case *uint64:
+		return atomic.CompareAndSwapUint64(p, uint64(old), uint64(n))
+	case *uint32:
+		return atomic.CompareAndSwapUint32(p, uint32(old), uint32(n))
+	case *float64:
+		return atomic.CompareAndSwapUint64((*uint64)(unsafe.Pointer(p)),
+			math.Float64bits(float64(old)), math.Float64bits(float64(n)))
+	case *float32:
+		return atomic.CompareAndSwapUint32((*uint32)(unsafe.Pointer(p)),
+			math.Float32bits(float32(old)), math.Float32bits(float32(n)))
+	}
+	panic("tracker: unsupported number type")
+}
+
+// add returns x+n, refusing results below zero or past the largest value
+// of T
+func add[T Number](x, n T) (T, error) {
+	next := x + n
+	if (n > 0 && next < x) || math.IsInf(float64(next), 1) {
+		return 0, ErrOverflow
+	}
+	if next < 0 {
+		return 0, ErrNegative
+	}
+	return next, nil
+}
+
+// raise atomically lifts *addr to at least n
+func raise[T Number](addr *T, n T) {
+	for {
+		cur := load(addr)
+		if cur >= n || cas(addr, cur, n) {
+			return
+		}
+	}
+}
+
+// exchange atomically sets *addr to n and returns the old value
+func exchange[T Number](addr *T, n T) T {
+	for {
+		cur := load(addr)
+		if cas(addr, cur, n) {
+			return cur
+		}
+	}
+}
+
+// deltaError reports a rejected update, as a *DeltaError for integer types
+// and as the bare sentinel for floats
+func deltaError[T Number](cur, n T, err error) error {
+	switch any(cur).(type) {
+	case float32, float64:
+		return err
+	}
+	return &DeltaError{Current: int64(cur), Delta: int64(n), Err: err}
+}
@@ -0,0 +1,86 @@
+package tracker
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_add(t *testing.T) {
+	t.Run("int32 overflow", func(t *testing.T) {
+		_, err := add[int32](math.MaxInt32-1, 2)
+		assert.True(t, errors.Is(err, ErrOverflow))
+	})
+	t.Run("uint64 overflow", func(t *testing.T) {
+		_, err := add[uint64](math.MaxUint64, 1)
+		assert.True(t, errors.Is(err, ErrOverflow))
+	})
+	t.Run("float32 overflow", func(t *testing.T) {
+		_, err := add[float32](math.MaxFloat32, math.MaxFloat32)
+		assert.True(t, errors.Is(err, ErrOverflow))
+	})
+	t.Run("int64 negative", func(t *testing.T) {
+		_, err := add[int64](1, -2)
+		assert.True(t, errors.Is(err, ErrNegative))
+	})
+	t.Run("uint32 in range", func(t *testing.T) {
+		got, err := add[uint32](40, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(42), got)
+	})
+}
+
+func testCounterOf[T Number](t *testing.T) {
+	c := NewCounterOf[T]()
+	var wg sync.WaitGroup
+	for x := 0; x < 4; x++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := 0; y < 250; y++ {
+				c.Current(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, T(1000), c.RawValue())
+
+	c.SetCurrent(3)
+	_, err := c.Current(0)
+	assert.NoError(t, err)
+	assert.Equal(t, T(3), *c.Pointer())
+	c.Reset()
+	assert.Equal(t, T(0), c.RawValue())
+}
+
+func TestCounterOf(t *testing.T) {
+	t.Run("int32", testCounterOf[int32])
+	t.Run("int64", testCounterOf[int64])
+	t.Run("uint32", testCounterOf[uint32])
+	t.Run("uint64", testCounterOf[uint64])
+	t.Run("float32", testCounterOf[float32])
+	t.Run("float64", testCounterOf[float64])
+}
+
+func TestGaugeOf(t *testing.T) {
+	g := NewBoundedGaugeOf[uint32](OverflowClamp)
+	g.SetTotal(10)
+	got, err := g.Current(25)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), got)
+
+	f := NewBoundedGaugeOf[float32](OverflowError)
+	f.SetTotal(1)
+	_, err = f.Current(1.5)
+	assert.True(t, errors.Is(err, ErrExceedsTotal))
+	var de *DeltaError
+	assert.False(t, errors.As(err, &de))
+
+	i := NewGaugeOf[int32]()
+	_, err = i.Current(-1)
+	assert.True(t, errors.As(err, &de))
+	assert.Equal(t, int64(-1), de.Delta)
+}
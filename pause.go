@@ -0,0 +1,90 @@
+package tracker
+
+import (
+	"sync"
+	"time"
+)
+
+// pauser keeps account of the time spent paused, so that it can be left
+// out of rates. Its zero value is ready to use with the real clock, and a
+// nil pauser is never paused.
+type pauser struct {
+	clock     Clock
+	paused    bool
+	since     time.Time
+	pausedFor time.Duration
+	lock      sync.Mutex
+}
+
+func (p *pauser) setClock(c Clock) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.clock = c
+}
+
+func (p *pauser) getClock() Clock {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.clock == nil {
+		return realClock
+	}
+	return p.clock
+}
+
+func (p *pauser) pause() {
+	now := p.getClock().Now()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.paused {
+		p.paused = true
+		p.since = now
+	}
+}
+
+func (p *pauser) resume() {
+	now := p.getClock().Now()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.paused {
+		p.paused = false
+		p.pausedFor += now.Sub(p.since)
+	}
+}
+
+func (p *pauser) isPaused() bool {
+	if p == nil {
+		return false
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.paused
+}
+
+// pausedAt returns the time spent paused up to now
+func (p *pauser) pausedAt(now time.Time) time.Duration {
+	if p == nil {
+		return 0
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	d := p.pausedFor
+	if p.paused && now.After(p.since) {
+		d += now.Sub(p.since)
+	}
+	return d
+}
+
+// activeClock is a Clock that stands still while p is paused, so
+// measurements taken with it only see active time
+type activeClock struct {
+	Clock
+	p *pauser
+}
+
+func (c activeClock) Now() time.Time {
+	now := c.Clock.Now()
+	return now.Add(-c.p.pausedAt(now))
+}
@@ -0,0 +1,158 @@
+package tracker
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// rateEngine turns measurements of a growing value into per second rates
+// and keeps the samples needed to summarise them
+type rateEngine[T Number] interface {
+	MeasureStart(Clock, T) func(T)
+	AvgRate() T
+	MinRate() T
+	MaxRate() T
+	Variance() float64
+	Samples() []T
+	Reset()
+	Values() (sampleSize uint, total T, listLen int)
+	SampleSize(...uint) uint
+}
+
+// sampleRate keeps the last sampleSize rate samples and averages them. A
+// sample size of zero keeps every sample.
+type sampleRate[T Number] struct {
+	sampleSize uint
+	total      T
+	list       *list.List
+	lock       sync.Mutex
+}
+
+func newSampleRate[T Number](n uint) *sampleRate[T] {
+	newSampleRate := &sampleRate[T]{
+		sampleSize: n,
+		list:       list.New(),
+	}
+	return newSampleRate
+}
+
+// MeasureStart starts a measurement of x and returns a function that ends
+// it and records the rate. Measurements that take no time are dropped.
+func (s *sampleRate[T]) MeasureStart(c Clock, x T) func(T) {
+	start := c.Now()
+	return func(m T) {
+		d := c.Now().Sub(start)
+		if d <= 0 {
+			return
+		}
+		s.add(perSecond(m-x, d))
+	}
+}
+
+func (s *sampleRate[T]) AvgRate() T {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.list.Len() == 0 {
+		return 0
+	}
+	return s.total / T(s.list.Len())
+}
+
+func (s *sampleRate[T]) MinRate() T {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.list.Len() == 0 {
+		return 0
+	}
+	min := s.list.Front().Value.(T)
+	for e := s.list.Front(); e != nil; e = e.Next() {
+		if r := e.Value.(T); r < min {
+			min = r
+		}
+	}
+	return min
+}
+
+func (s *sampleRate[T]) MaxRate() T {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.list.Len() == 0 {
+		return 0
+	}
+	max := s.list.Front().Value.(T)
+	for e := s.list.Front(); e != nil; e = e.Next() {
+		if r := e.Value.(T); r > max {
+			max = r
+		}
+	}
+	return max
+}
+
+// Variance returns the population variance of the samples
+func (s *sampleRate[T]) Variance() float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	n := s.list.Len()
+	if n == 0 {
+		return 0
+	}
+	mean := float64(s.total) / float64(n)
+	var sum float64
+	for e := s.list.Front(); e != nil; e = e.Next() {
+		d := float64(e.Value.(T)) - mean
+		sum += d * d
+	}
+	return sum / float64(n)
+}
+
+// Samples returns the samples in the window, oldest first
+func (s *sampleRate[T]) Samples() []T {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	samples := make([]T, 0, s.list.Len())
+	for e := s.list.Back(); e != nil; e = e.Prev() {
+		samples = append(samples, e.Value.(T))
+	}
+	return samples
+}
+
+func (s *sampleRate[T]) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.total = 0
+	s.list = list.New()
+}
+
+func (s *sampleRate[T]) Values() (sampleSize uint, total T, listLen int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.sampleSize, s.total, s.list.Len()
+}
+
+func (s *sampleRate[T]) SampleSize(n ...uint) uint {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(n) != 0 {
+		s.sampleSize = n[0]
+	}
+	return s.sampleSize
+}
+
+func (s *sampleRate[T]) add(r T) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.list.PushFront(r)
+	s.total += r
+	if s.sampleSize != 0 && s.list.Len() > int(s.sampleSize) {
+		e := s.list.Back()
+		s.total -= e.Value.(T)
+		s.list.Remove(e)
+	}
+}
+
+// perSecond converts a change of delta over d into a per second rate.
+// Whole second measurements match integer division of delta by seconds.
+func perSecond[T Number](delta T, d time.Duration) T {
+	return T(float64(delta) / d.Seconds())
+}
@@ -0,0 +1,119 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/morrocker/tracker/trackertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sampleRate_add(t *testing.T) {
+	type want struct {
+		avg      int64
+		min      int64
+		max      int64
+		variance float64
+		samples  []int64
+	}
+	tests := []struct {
+		name       string
+		sampleSize uint
+		rates      []int64
+		want       want
+	}{
+		{
+			name:       "empty",
+			sampleSize: 3,
+			want: want{
+				samples: []int64{},
+			},
+		},
+		{
+			name:       "window not full",
+			sampleSize: 3,
+			rates:      []int64{2, 4},
+			want: want{
+				avg:      3,
+				min:      2,
+				max:      4,
+				variance: 1,
+				samples:  []int64{2, 4},
+			},
+		},
+		{
+			name:       "oldest samples dropped",
+			sampleSize: 3,
+			rates:      []int64{100, 1, 2, 9},
+			want: want{
+				avg:      4,
+				min:      1,
+				max:      9,
+				variance: 12.666666666666666,
+				samples:  []int64{1, 2, 9},
+			},
+		},
+		{
+			name:       "zero sample size keeps everything",
+			sampleSize: 0,
+			rates:      []int64{5, 5, 5, 5, 5, 5},
+			want: want{
+				avg:     5,
+				min:     5,
+				max:     5,
+				samples: []int64{5, 5, 5, 5, 5, 5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSampleRate[int64](tt.sampleSize)
+			for _, r := range tt.rates {
+				s.add(r)
+			}
+			assert.Equal(t, tt.want.avg, s.AvgRate())
+			assert.Equal(t, tt.want.min, s.MinRate())
+			assert.Equal(t, tt.want.max, s.MaxRate())
+			assert.InDelta(t, tt.want.variance, s.Variance(), 1e-9)
+			assert.Equal(t, tt.want.samples, s.Samples())
+		})
+	}
+}
+
+func Test_sampleRate_MeasureStart(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		delta   int64
+		want    []int64
+	}{
+		{
+			name:    "whole seconds",
+			elapsed: 4 * time.Second,
+			delta:   100,
+			want:    []int64{25},
+		},
+		{
+			name:    "sub second",
+			elapsed: 250 * time.Millisecond,
+			delta:   100,
+			want:    []int64{400},
+		},
+		{
+			name:    "no time elapsed",
+			elapsed: 0,
+			delta:   100,
+			want:    []int64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := trackertest.NewClock(epoch)
+			s := newSampleRate[int64](5)
+			end := s.MeasureStart(clk, 50)
+			clk.Advance(tt.elapsed)
+			end(50 + tt.delta)
+			assert.Equal(t, tt.want, s.Samples())
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package tracker
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Format selects the field separator used by a Recorder
+type Format int
+
+const (
+	CSV Format = iota
+	TSV
+)
+
+// maxRotations bounds the path.N names tried when moving a file aside
+const maxRotations = 10000
+
+// ErrNoRotation is returned when a Recorder finds no free path.N name to
+// move its output aside to
+var ErrNoRotation = errors.New("tracker: no free name to rotate the recording to")
+
+// Recorder samples a Set at a fixed interval and appends one row per sample
+// to a CSV or TSV file, for plotting runs after the fact. Each row holds a
+// timestamp followed by the raw value of every tracker, plus its total and
+// rate columns when present.
+type Recorder interface {
+	Format(Format)
+	MaxSize(int64)
+	SetClock(Clock)
+	Record() error
+	Start() error
+	Stop() error
+}
+
+type recorder struct {
+	set      Set
+	path     string
+	interval time.Duration
+	format   Format
+	maxSize  int64
+	file     *os.File
+	writer   *csv.Writer
+	written  *countWriter
+	header   []string
+	err      error
+	clock    Clock
+	ticker   Ticker
+	lock     sync.Mutex
+}
+
+// NewRecorder returns a Recorder sampling s into the file at path every d.
+// Intervals that are not positive use DefaultInterval.
+func NewRecorder(s Set, path string, d time.Duration) Recorder {
+	newRecorder := &recorder{
+		set:      s,
+		path:     path,
+		interval: validInterval(d),
+		clock:    realClock,
+	}
+	return newRecorder
+}
+
+// Format sets the output format. It applies to the next file opened.
+func (r *recorder) Format(f Format) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.format = f
+}
+
+// MaxSize rotates the output once it grows beyond n bytes. Zero disables
+// rotation.
+func (r *recorder) MaxSize(n int64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.maxSize = n
+}
+
+func (r *recorder) SetClock(c Clock) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.clock = c
+}
+
+// Record appends a single row with the current state of the set
+func (r *recorder) Record() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.record(r.clock.Now())
+}
+
+func (r *recorder) Start() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.ticker != nil {
+		return nil
+	}
+	if err := r.record(r.clock.Now()); err != nil {
+		return err
+	}
+	r.ticker = r.clock.NewTicker(r.interval, func(t time.Time) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		if err := r.record(t); err != nil && r.err == nil {
+			r.err = err
+		}
+	})
+	return nil
+}
+
+// Stop halts recording, closes the output file and returns the first error
+// encountered while recording in the background
+func (r *recorder) Stop() error {
+	r.lock.Lock()
+	ticker := r.ticker
+	r.ticker = nil
+	r.lock.Unlock()
+	if ticker != nil {
+		ticker.Stop()
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	err := r.err
+	r.err = nil
+	if cerr := r.close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (r *recorder) record(t time.Time) error {
+	readings := r.set.Read()
+	header := columns(readings)
+	if r.file != nil && !equalColumns(r.header, header) {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+	if r.file == nil {
+		if err := r.open(header); err != nil {
+			return err
+		}
+	}
+	row := []string{t.Format(time.RFC3339Nano)}
+	for _, rd := range readings {
+		row = append(row, strconv.FormatInt(rd.Current, 10))
+		if rd.HasTotal {
+			row = append(row, strconv.FormatInt(rd.Total, 10))
+		}
+		if rd.HasRate {
+			row = append(row, strconv.FormatInt(rd.Rate, 10))
+		}
+	}
+	if err := r.write(row); err != nil {
+		return err
+	}
+	if r.maxSize > 0 && r.written.n >= r.maxSize {
+		return r.rotate()
+	}
+	return nil
+}
+
+// open starts a new file with the given header. A file left at path by an
+// earlier recording is moved aside first rather than truncated.
+func (r *recorder) open(header []string) error {
+	if _, err := os.Stat(r.path); err == nil {
+		if err := r.moveAside(); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	r.file = f
+	r.written = &countWriter{f: f}
+	r.writer = csv.NewWriter(r.written)
+	if r.format == TSV {
+		r.writer.Comma = '\t'
+	}
+	r.header = header
+	return r.write(header)
+}
+
+func (r *recorder) write(row []string) error {
+	if err := r.writer.Write(row); err != nil {
+		return err
+	}
+	r.writer.Flush()
+	return r.writer.Error()
+}
+
+// rotate closes the current file and moves it aside to the first free
+// path.N name, so the next row starts a fresh file with its own header
+func (r *recorder) rotate() error {
+	if err := r.close(); err != nil {
+		return err
+	}
+	return r.moveAside()
+}
+
+// moveAside renames the file at path to the first free path.N name
+func (r *recorder) moveAside() error {
+	for n := 1; n <= maxRotations; n++ {
+		name := fmt.Sprintf("%s.%d", r.path, n)
+		_, err := os.Stat(name)
+		switch {
+		case os.IsNotExist(err):
+			return os.Rename(r.path, name)
+		case err != nil:
+			return err
+		}
+	}
+	return ErrNoRotation
+}
+
+func (r *recorder) close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file, r.writer, r.written, r.header = nil, nil, nil, nil
+	return err
+}
+
+func columns(readings []Reading) []string {
+	header := []string{"timestamp"}
+	for _, rd := range readings {
+		header = append(header, rd.Name)
+		if rd.HasTotal {
+			header = append(header, rd.Name+".total")
+		}
+		if rd.HasRate {
+			header = append(header, rd.Name+".rate")
+		}
+	}
+	return header
+}
+
+func equalColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type countWriter struct {
+	f *os.File
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.f.Write(p)
+	w.n += int64(n)
+	return n, err
+}
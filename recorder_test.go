@@ -0,0 +1,173 @@
+package tracker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morrocker/tracker/trackertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func readRows(t *testing.T, path string) [][]string {
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	rows := [][]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		rows = append(rows, strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == '\t'
+		}))
+	}
+	return rows
+}
+
+func TestRecorder_Record(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		sep    string
+	}{
+		{
+			name:   "csv",
+			format: CSV,
+			sep:    ",",
+		},
+		{
+			name:   "tsv",
+			format: TSV,
+			sep:    "\t",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "run.out")
+			c := &counter[int64]{current: 3}
+			g := &gauge[int64]{current: 10, total: 40}
+			s := NewSet()
+			s.AddCounter("files", c)
+			s.AddGauge("bytes", g)
+			s.AddSpeed("bytes", &fixedSpeed{rate: 7})
+
+			r := NewRecorder(s, path, time.Second)
+			r.Format(tt.format)
+			assert.NoError(t, r.Record())
+			c.Current(1)
+			assert.NoError(t, r.Record())
+			assert.NoError(t, r.Stop())
+
+			b, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+			assert.Len(t, lines, 3)
+			assert.Equal(t, strings.Join([]string{"timestamp", "files", "bytes", "bytes.total", "bytes.rate"}, tt.sep), lines[0])
+			assert.True(t, strings.HasSuffix(lines[1], strings.Join([]string{"", "3", "10", "40", "7"}, tt.sep)))
+			assert.True(t, strings.HasSuffix(lines[2], strings.Join([]string{"", "4", "10", "40", "7"}, tt.sep)))
+		})
+	}
+}
+
+func TestRecorder_Rotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.csv")
+	s := NewSet()
+	s.AddCounter("files", NewCounter())
+
+	r := NewRecorder(s, path, time.Second)
+	r.MaxSize(1)
+	assert.NoError(t, r.Record())
+	assert.NoError(t, r.Record())
+	s.AddCounter("errors", NewCounter())
+	r.MaxSize(0)
+	assert.NoError(t, r.Record())
+	assert.NoError(t, r.Stop())
+
+	for _, name := range []string{"run.csv.1", "run.csv.2"} {
+		rows := readRows(t, filepath.Join(dir, name))
+		assert.Len(t, rows, 2)
+		assert.Equal(t, []string{"timestamp", "files"}, rows[0])
+	}
+	rows := readRows(t, path)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, []string{"timestamp", "files", "errors"}, rows[0])
+}
+
+func TestRecorder_StartStop(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	path := filepath.Join(t.TempDir(), "run.csv")
+	c := NewCounter()
+	s := NewSet()
+	s.AddCounter("files", c)
+
+	r := NewRecorder(s, path, time.Second)
+	r.SetClock(clk)
+	assert.NoError(t, r.Start())
+	for x := 0; x < 3; x++ {
+		c.Current(2)
+		clk.Advance(time.Second)
+	}
+	assert.NoError(t, r.Stop())
+	clk.Advance(time.Second)
+
+	assert.Equal(t, [][]string{
+		{"timestamp", "files"},
+		{"2021-06-05T00:00:00Z", "0"},
+		{"2021-06-05T00:00:01Z", "2"},
+		{"2021-06-05T00:00:02Z", "4"},
+		{"2021-06-05T00:00:03Z", "6"},
+	}, readRows(t, path))
+}
+
+func TestRecorder_RecordAfterStop(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.csv")
+	c := NewCounter()
+	s := NewSet()
+	s.AddCounter("files", c)
+
+	r := NewRecorder(s, path, time.Second)
+	assert.NoError(t, r.Record())
+	assert.NoError(t, r.Record())
+	assert.NoError(t, r.Stop())
+	c.Current(5)
+	assert.NoError(t, r.Record())
+	assert.NoError(t, r.Stop())
+
+	rows := readRows(t, filepath.Join(dir, "run.csv.1"))
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "0", rows[2][1])
+	rows = readRows(t, path)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, []string{"timestamp", "files"}, rows[0])
+	assert.Equal(t, "5", rows[1][1])
+}
+
+func TestRecorder_InvalidInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.csv")
+	s := NewSet()
+	s.AddCounter("files", NewCounter())
+	r := NewRecorder(s, path, -time.Second)
+	assert.NotPanics(t, func() { assert.NoError(t, r.Start()) })
+	assert.NoError(t, r.Stop())
+}
+
+func TestRecorder_RotateError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), strings.Repeat("r", 255))
+	s := NewSet()
+	s.AddCounter("files", NewCounter())
+	r := NewRecorder(s, path, time.Second)
+	r.MaxSize(1)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Record()
+	}()
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("record did not return")
+	}
+	r.Stop()
+}
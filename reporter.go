@@ -0,0 +1,161 @@
+package tracker
+
+import (
+	"sync"
+	"time"
+)
+
+// Logger receives structured log lines as a message followed by alternating
+// keys and values, in the style of log/slog
+type Logger interface {
+	Log(msg string, keyvals ...interface{})
+}
+
+// LoggerFunc adapts a plain function to the Logger interface
+type LoggerFunc func(msg string, keyvals ...interface{})
+
+// Log calls f(msg, keyvals...)
+func (f LoggerFunc) Log(msg string, keyvals ...interface{}) {
+	f(msg, keyvals...)
+}
+
+// Reporter periodically writes the state of a Set as a single log line
+type Reporter interface {
+	Interval(time.Duration)
+	OnlyOnChange(bool)
+	SetClock(Clock)
+	Report()
+	Start()
+	Stop()
+}
+
+type reporter struct {
+	set      Set
+	logger   Logger
+	interval time.Duration
+	onChange bool
+	last     []Reading
+	started  time.Time
+	clock    Clock
+	ticker   Ticker
+	lock     sync.Mutex
+}
+
+// NewReporter returns a Reporter logging s to l every d. Intervals that
+// are not positive use DefaultInterval.
+func NewReporter(s Set, l Logger, d time.Duration) Reporter {
+	newReporter := &reporter{
+		set:      s,
+		logger:   l,
+		interval: validInterval(d),
+		clock:    realClock,
+	}
+	return newReporter
+}
+
+// Interval changes the time between reports, taking effect immediately if
+// the reporter is running. Intervals that are not positive use
+// DefaultInterval.
+func (r *reporter) Interval(d time.Duration) {
+	d = validInterval(d)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.interval = d
+	if r.ticker != nil {
+		r.ticker.Reset(d)
+	}
+}
+
+// OnlyOnChange skips reports whose raw values match the last logged report
+func (r *reporter) OnlyOnChange(b bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.onChange = b
+}
+
+func (r *reporter) SetClock(c Clock) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.clock = c
+}
+
+// Report logs the current state of the set
+func (r *reporter) Report() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.report()
+}
+
+func (r *reporter) Start() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.ticker != nil {
+		return
+	}
+	r.started = r.clock.Now()
+	r.ticker = r.clock.NewTicker(r.interval, func(time.Time) {
+		r.Report()
+	})
+}
+
+// Stop halts periodic reporting and logs a final summary line
+func (r *reporter) Stop() {
+	r.lock.Lock()
+	ticker := r.ticker
+	r.ticker = nil
+	r.lock.Unlock()
+	if ticker == nil {
+		return
+	}
+	ticker.Stop()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	readings := r.set.Read()
+	keyvals := append(fields(readings), "elapsed", r.clock.Now().Sub(r.started))
+	r.logger.Log("tracker summary", keyvals...)
+	r.last = readings
+}
+
+func (r *reporter) report() {
+	readings := r.set.Read()
+	if r.onChange && r.last != nil && !changed(r.last, readings) {
+		return
+	}
+	r.logger.Log("tracker report", fields(readings)...)
+	r.last = readings
+}
+
+func changed(a, b []Reading) bool {
+	if len(a) != len(b) {
+		return true
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Current != b[i].Current ||
+			a[i].Total != b[i].Total || a[i].Rate != b[i].Rate {
+			return true
+		}
+	}
+	return false
+}
+
+func fields(readings []Reading) []interface{} {
+	keyvals := []interface{}{}
+	for _, r := range readings {
+		keyvals = append(keyvals, r.Name+".current", r.Current, r.Name+".value", r.Value)
+		if r.HasTotal {
+			keyvals = append(keyvals,
+				r.Name+".total", r.Total,
+				r.Name+".total_value", r.TotalValue,
+				r.Name+".percent", r.Percent,
+			)
+		}
+		if r.HasRate {
+			keyvals = append(keyvals, r.Name+".rate", r.Rate, r.Name+".rate_value", r.RateValue)
+		}
+		if r.HasTotal && r.HasRate {
+			keyvals = append(keyvals, r.Name+".eta", r.ETA)
+		}
+	}
+	return keyvals
+}
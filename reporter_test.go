@@ -0,0 +1,120 @@
+package tracker
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/morrocker/tracker/trackertest"
+	"github.com/stretchr/testify/assert"
+)
+
+type logLine struct {
+	msg     string
+	keyvals []interface{}
+}
+
+type testLogger struct {
+	lines []logLine
+	lock  sync.Mutex
+}
+
+func (l *testLogger) Log(msg string, keyvals ...interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.lines = append(l.lines, logLine{msg: msg, keyvals: keyvals})
+}
+
+func TestReporter_Report(t *testing.T) {
+	units := func(n int64) string {
+		return fmt.Sprintf("%dB", n)
+	}
+	g := &gauge[int64]{current: 25, total: 100}
+	g.UnitsFunc(units)
+	s := NewSet()
+	s.AddGauge("upload", g)
+	s.AddSpeed("upload", &fixedSpeed{rate: 5})
+	l := &testLogger{}
+	r := NewReporter(s, l, time.Second)
+
+	r.Report()
+	want := []interface{}{
+		"upload.current", int64(25),
+		"upload.value", "25B",
+		"upload.total", int64(100),
+		"upload.total_value", "100B",
+		"upload.percent", float64(25),
+		"upload.rate", int64(5),
+		"upload.rate_value", "5/s",
+		"upload.eta", 15 * time.Second,
+	}
+	assert.Equal(t, []logLine{{msg: "tracker report", keyvals: want}}, l.lines)
+}
+
+func TestReporter_OnlyOnChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		onChange bool
+		want     int
+	}{
+		{
+			name:     "always report",
+			onChange: false,
+			want:     3,
+		},
+		{
+			name:     "only on change",
+			onChange: true,
+			want:     2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCounter()
+			s := NewSet()
+			s.AddCounter("files", c)
+			l := &testLogger{}
+			r := NewReporter(s, l, time.Second)
+			r.OnlyOnChange(tt.onChange)
+			r.Report()
+			r.Report()
+			c.Current(1)
+			r.Report()
+			assert.Equal(t, tt.want, len(l.lines))
+		})
+	}
+}
+
+func TestReporter_StartStop(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	s := NewSet()
+	s.AddCounter("files", NewCounter())
+	l := &testLogger{}
+	r := NewReporter(s, l, time.Second)
+	r.SetClock(clk)
+	r.Start()
+	clk.Advance(3 * time.Second)
+	assert.Equal(t, 3, len(l.lines))
+	r.Interval(2 * time.Second)
+	clk.Advance(3 * time.Second)
+	assert.Equal(t, 4, len(l.lines))
+	r.Stop()
+
+	assert.Equal(t, 5, len(l.lines))
+	last := l.lines[4]
+	assert.Equal(t, "tracker summary", last.msg)
+	assert.Equal(t, []interface{}{"elapsed", 6 * time.Second}, last.keyvals[len(last.keyvals)-2:])
+	clk.Advance(3 * time.Second)
+	assert.Equal(t, 5, len(l.lines))
+}
+
+func TestReporter_InvalidInterval(t *testing.T) {
+	s := NewSet()
+	l := &testLogger{}
+	r := NewReporter(s, l, 0)
+	assert.NotPanics(t, r.Start)
+	assert.NotPanics(t, func() { r.Interval(-time.Second) })
+	r.Stop()
+	assert.Equal(t, DefaultInterval, r.(*reporter).interval)
+}
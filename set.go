@@ -0,0 +1,173 @@
+package tracker
+
+import (
+	"sync"
+	"time"
+)
+
+// Reading is a point in time view of a named tracker
+type Reading struct {
+	Name       string        `json:"name"`
+	Current    int64         `json:"current"`
+	Total      int64         `json:"total"`
+	Value      string        `json:"value"`
+	TotalValue string        `json:"total_value"`
+	Percent    float64       `json:"percent"`
+	Rate       int64         `json:"rate"`
+	RateValue  string        `json:"rate_value"`
+	ETA        time.Duration `json:"eta"`
+	HasTotal   bool          `json:"has_total"`
+	HasRate    bool          `json:"has_rate"`
+}
+
+// Set groups trackers under a name so they can be read together. A Speed
+// added under the same name as a Counter or Gauge is read alongside it.
+type Set interface {
+	AddCounter(string, Counter)
+	AddGauge(string, Gauge)
+	AddSpeed(string, Speed)
+	Counter(string) Counter
+	Gauge(string) Gauge
+	Remove(string)
+	Names() []string
+	Read() []Reading
+	Snapshot() Snapshot
+	SetClock(Clock)
+}
+
+type set struct {
+	names   []string
+	entries map[string]*entry
+	clock   Clock
+	lock    sync.RWMutex
+}
+
+type entry struct {
+	counter Counter
+	gauge   Gauge
+	speed   Speed
+}
+
+func NewSet() Set {
+	newSet := &set{
+		entries: make(map[string]*entry),
+		clock:   realClock,
+	}
+	return newSet
+}
+
+func (s *set) AddCounter(name string, c Counter) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e := s.entry(name)
+	e.counter, e.gauge = c, nil
+}
+
+func (s *set) AddGauge(name string, g Gauge) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e := s.entry(name)
+	e.gauge, e.counter = g, nil
+}
+
+func (s *set) AddSpeed(name string, sp Speed) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.entry(name).speed = sp
+}
+
+// Counter returns the Counter added under name, or nil
+func (s *set) Counter(name string) Counter {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if e, ok := s.entries[name]; ok {
+		return e.counter
+	}
+	return nil
+}
+
+// Gauge returns the Gauge added under name, or nil
+func (s *set) Gauge(name string) Gauge {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if e, ok := s.entries[name]; ok {
+		return e.gauge
+	}
+	return nil
+}
+
+func (s *set) Remove(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.entries[name]; !ok {
+		return
+	}
+	delete(s.entries, name)
+	for i, n := range s.names {
+		if n == name {
+			s.names = append(s.names[:i], s.names[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *set) Names() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	names := make([]string, len(s.names))
+	copy(names, s.names)
+	return names
+}
+
+// Read returns a Reading for every tracker in the order they were added
+func (s *set) Read() []Reading {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	readings := make([]Reading, 0, len(s.names))
+	for _, name := range s.names {
+		readings = append(readings, s.entries[name].read(name))
+	}
+	return readings
+}
+
+func (s *set) entry(name string) *entry {
+	e, ok := s.entries[name]
+	if !ok {
+		e = &entry{}
+		s.entries[name] = e
+		s.names = append(s.names, name)
+	}
+	return e
+}
+
+func (e *entry) read(name string) Reading {
+	r := Reading{Name: name}
+	switch {
+	case e.gauge != nil:
+		r.Current, r.Total = e.gauge.RawValues()
+		r.Value, r.TotalValue = e.gauge.Values()
+		r.HasTotal = true
+		if r.Total > 0 {
+			r.Percent = float64(r.Current) * 100 / float64(r.Total)
+		}
+	case e.counter != nil:
+		r.Current = e.counter.RawValue()
+		r.Value = e.counter.Value()
+	}
+	if e.speed != nil {
+		r.Rate = e.speed.RawRate()
+		r.RateValue = e.speed.Rate()
+		r.HasRate = true
+	}
+	if r.HasTotal && r.HasRate {
+		r.ETA = eta(r.Current, r.Total, r.Rate)
+	}
+	return r
+}
+
+func eta[T Number](current, total, rate T) time.Duration {
+	if rate <= 0 || current >= total {
+		return 0
+	}
+	return time.Duration(float64(total-current) / float64(rate) * float64(time.Second))
+}
@@ -0,0 +1,104 @@
+package tracker
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedSpeed struct {
+	Speed
+	rate int64
+}
+
+func (f *fixedSpeed) RawRate() int64 { return f.rate }
+func (f *fixedSpeed) Rate() string   { return fmt.Sprintf("%d/s", f.rate) }
+
+func TestSet_Read(t *testing.T) {
+	units := func(n int64) string {
+		return fmt.Sprintf("%d", n)
+	}
+	tests := []struct {
+		name    string
+		current int64
+		total   int64
+		rate    int64
+		want    Reading
+	}{
+		{
+			name:    "half done",
+			current: 50,
+			total:   100,
+			rate:    10,
+			want: Reading{
+				Name:       "upload",
+				Current:    50,
+				Total:      100,
+				Value:      "50",
+				TotalValue: "100",
+				Percent:    50,
+				Rate:       10,
+				RateValue:  "10/s",
+				ETA:        5 * time.Second,
+				HasTotal:   true,
+				HasRate:    true,
+			},
+		},
+		{
+			name:    "no rate yet",
+			current: 0,
+			total:   0,
+			rate:    0,
+			want: Reading{
+				Name:       "upload",
+				Value:      "0",
+				TotalValue: "0",
+				RateValue:  "0/s",
+				HasTotal:   true,
+				HasRate:    true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &gauge[int64]{current: tt.current, total: tt.total}
+			g.UnitsFunc(units)
+			s := NewSet()
+			s.AddGauge("upload", g)
+			s.AddSpeed("upload", &fixedSpeed{rate: tt.rate})
+			got := s.Read()
+			assert.Equal(t, []Reading{tt.want}, got)
+		})
+	}
+}
+
+func TestSet_AddRemove(t *testing.T) {
+	s := NewSet()
+	s.AddCounter("files", NewCounter())
+	s.AddGauge("bytes", NewGauge())
+	s.AddCounter("errors", NewCounter())
+	assert.Equal(t, []string{"files", "bytes", "errors"}, s.Names())
+
+	s.Remove("bytes")
+	s.Remove("missing")
+	assert.Equal(t, []string{"files", "errors"}, s.Names())
+
+	readings := s.Read()
+	assert.Len(t, readings, 2)
+	assert.False(t, readings[0].HasTotal)
+	assert.False(t, readings[0].HasRate)
+}
+
+func TestSet_Lookup(t *testing.T) {
+	s := NewSet()
+	files := NewCounter()
+	bytes := NewGauge()
+	s.AddCounter("files", files)
+	s.AddGauge("bytes", bytes)
+	assert.Equal(t, files, s.Counter("files"))
+	assert.Equal(t, bytes, s.Gauge("bytes"))
+	assert.Nil(t, s.Counter("bytes"))
+	assert.Nil(t, s.Gauge("missing"))
+}
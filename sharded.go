@@ -0,0 +1,132 @@
+package tracker
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// cacheLine is the assumed size of a CPU cache line
+const cacheLine = 64
+
+type shard struct {
+	n int64
+	_ [cacheLine - 8]byte
+}
+
+type shardedCounter struct {
+	shards    []shard
+	next      uint32
+	pool      sync.Pool
+	unitsFunc atomicUnits[int64]
+	epoch     resetEpoch[int64]
+	lock      sync.Mutex
+}
+
+// NewShardedCounter returns an up/down Counter for hot paths that spreads
+// increments over n cache line sized shards, so concurrent writers rarely
+// touch the same memory. Zero or less uses one shard per GOMAXPROCS.
+//
+// Reads sum every shard, so the value Current returns may already include
+// concurrent updates. Decrements, SetCurrent and Swap take a lock, which
+// keeps the value from going below zero.
+func NewShardedCounter(n int) Counter {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	c := &shardedCounter{
+		shards: make([]shard, n),
+	}
+	// the pool keeps a shard index per P, so a goroutine tends to keep
+	// hitting the shard of the processor it runs on
+	c.pool.New = func() interface{} {
+		i := int(atomic.AddUint32(&c.next, 1)) % len(c.shards)
+		return &i
+	}
+	return c
+}
+
+// SetCurrent sets the value to n. Values below zero are ignored.
+func (c *shardedCounter) SetCurrent(n int64) {
+	if n < 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i := range c.shards {
+		atomic.StoreInt64(&c.shards[i].n, 0)
+	}
+	atomic.StoreInt64(&c.shards[0].n, n)
+}
+
+// Current adds n to one of the shards and returns the new sum. Decrements
+// that would take the value below zero are rejected with an error wrapping
+// ErrNegative.
+func (c *shardedCounter) Current(n int64) (int64, error) {
+	if n < 0 {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		if cur := c.RawValue(); cur+n < 0 {
+			return 0, deltaError(cur, n, ErrNegative)
+		}
+	}
+	i := c.pool.Get().(*int)
+	atomic.AddInt64(&c.shards[*i].n, n)
+	c.pool.Put(i)
+	return c.RawValue(), nil
+}
+
+func (c *shardedCounter) RawValue() int64 {
+	var sum int64
+	for i := range c.shards {
+		sum += atomic.LoadInt64(&c.shards[i].n)
+	}
+	return sum
+}
+
+func (c *shardedCounter) Value() string {
+	return c.unitsFunc.format(c.RawValue())
+}
+
+func (c *shardedCounter) UnitsFunc(f func(int64) string) {
+	c.unitsFunc.set(f)
+}
+
+func (c *shardedCounter) Reset() {
+	c.Swap(0)
+}
+
+// Swap empties every shard and returns what they held. Unlike SetCurrent
+// it loses no concurrent updates. Values below zero are treated as zero.
+func (c *shardedCounter) Swap(n int64) int64 {
+	if n < 0 {
+		n = 0
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.epoch.swap(n, func(n int64) int64 {
+		var sum int64
+		for i := range c.shards {
+			sum += atomic.SwapInt64(&c.shards[i].n, 0)
+		}
+		atomic.AddInt64(&c.shards[0].n, n)
+		return sum
+	})
+}
+
+func (c *shardedCounter) resets() *resetEpoch[int64] {
+	return &c.epoch
+}
+
+// Pointer returns the address of the first shard, as no single word holds
+// the value. Atomic adds through it count towards the value, but loads only
+// see that shard's share of it, so read the value with RawValue.
+func (c *shardedCounter) Pointer() *int64 {
+	return &c.shards[0].n
+}
+
+func (c *shardedCounter) Cursor() Cursor[int64] {
+	return newCursor(func() int64 {
+		return c.epoch.read(c.RawValue)
+	})
+}
@@ -0,0 +1,130 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/morrocker/tracker/trackertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewShardedCounter(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		want int
+	}{
+		{
+			name: "explicit shards",
+			n:    4,
+			want: 4,
+		},
+		{
+			name: "default shards",
+			n:    0,
+			want: runtime.GOMAXPROCS(0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewShardedCounter(tt.n).(*shardedCounter)
+			assert.Len(t, c.shards, tt.want)
+			assert.Equal(t, uintptr(cacheLine), unsafe.Sizeof(c.shards[0]))
+		})
+	}
+}
+
+func Test_shardedCounter_Current(t *testing.T) {
+	c := NewShardedCounter(8)
+	var wg sync.WaitGroup
+	for x := 0; x < 64; x++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := 0; y < 1000; y++ {
+				c.Current(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(64000), c.RawValue())
+
+	got, err := c.Current(-4000)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(60000), got)
+	got, err = c.Current(5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(60005), got)
+
+	got, err = c.Current(-60006)
+	assert.True(t, errors.Is(err, ErrNegative))
+	assert.Equal(t, int64(0), got)
+	assert.Equal(t, int64(60005), c.RawValue())
+	c.UnitsFunc(func(n int64) string {
+		return fmt.Sprintf("%d items", n)
+	})
+	assert.Equal(t, "60005 items", c.Value())
+
+	c.SetCurrent(7)
+	assert.Equal(t, int64(7), c.RawValue())
+	c.SetCurrent(-7)
+	assert.Equal(t, int64(7), c.RawValue())
+	c.Reset()
+	assert.Equal(t, int64(0), c.RawValue())
+
+	atomic.AddInt64(c.Pointer(), 3)
+	assert.Equal(t, int64(3), c.RawValue())
+}
+
+func Test_shardedCounter_Counter(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	c := NewShardedCounter(4)
+	s := NewSet()
+	s.AddCounter("files", c)
+	sp := NewCounterSpeed(c, 5)
+	sp.SetClock(clk)
+	end := sp.StartMeasure()
+	c.Current(10)
+	clk.Advance(time.Second)
+	end()
+	assert.Equal(t, int64(10), sp.RawRate())
+	assert.Equal(t, int64(10), s.Read()[0].Current)
+}
+
+func BenchmarkCounterContention(b *testing.B) {
+	counters := []struct {
+		name string
+		new  func() Counter
+	}{
+		{
+			name: "counter",
+			new:  NewCounter,
+		},
+		{
+			name: "sharded",
+			new: func() Counter {
+				return NewShardedCounter(0)
+			},
+		},
+	}
+	for _, procs := range []int{1, 2, 4, 8, 16} {
+		for _, cc := range counters {
+			b.Run(fmt.Sprintf("%s/procs=%d", cc.name, procs), func(b *testing.B) {
+				defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+				c := cc.new()
+				b.SetParallelism(64 / procs)
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						c.Current(1)
+					}
+				})
+			})
+		}
+	}
+}
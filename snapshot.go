@@ -0,0 +1,160 @@
+package tracker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Snapshot is an immutable copy of the readings of a Set at one time
+type Snapshot struct {
+	at       time.Time
+	readings []Reading
+}
+
+// NewSnapshot returns a Snapshot of readings taken at t, as when comparing
+// against a stored run
+func NewSnapshot(t time.Time, readings []Reading) Snapshot {
+	r := make([]Reading, len(readings))
+	copy(r, readings)
+	return Snapshot{at: t, readings: r}
+}
+
+// Snapshot reads every tracker in the set at the time of the set's clock
+func (s *set) Snapshot() Snapshot {
+	s.lock.RLock()
+	at := s.clock.Now()
+	s.lock.RUnlock()
+	return Snapshot{at: at, readings: s.Read()}
+}
+
+// SetClock sets the clock that timestamps snapshots
+func (s *set) SetClock(c Clock) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.clock = c
+}
+
+// Time returns when the snapshot was taken
+func (s Snapshot) Time() time.Time {
+	return s.at
+}
+
+// Readings returns a copy of the readings in the order they were taken
+func (s Snapshot) Readings() []Reading {
+	r := make([]Reading, len(s.readings))
+	copy(r, s.readings)
+	return r
+}
+
+// Reading returns the reading named name
+func (s Snapshot) Reading(name string) (Reading, bool) {
+	for _, r := range s.readings {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Reading{}, false
+}
+
+// TrackerDiff is the change of a tracker present in both snapshots of a
+// SnapshotDiff
+type TrackerDiff struct {
+	Name    string
+	Before  Reading
+	After   Reading
+	Current int64
+	Total   int64
+	// Rate is the change of current per second over the interval
+	Rate int64
+}
+
+// SnapshotDiff is the difference between two snapshots
+type SnapshotDiff struct {
+	From     time.Time
+	To       time.Time
+	Interval time.Duration
+	Trackers []TrackerDiff
+	Added    []Reading
+	Removed  []Reading
+}
+
+// Diff compares s with a later snapshot. Trackers are listed in the order
+// of the later snapshot, removed ones in the order of s.
+func (s Snapshot) Diff(later Snapshot) SnapshotDiff {
+	d := SnapshotDiff{
+		From:     s.at,
+		To:       later.at,
+		Interval: later.at.Sub(s.at),
+		Trackers: []TrackerDiff{},
+		Added:    []Reading{},
+		Removed:  []Reading{},
+	}
+	for _, after := range later.readings {
+		before, ok := s.Reading(after.Name)
+		if !ok {
+			d.Added = append(d.Added, after)
+			continue
+		}
+		td := TrackerDiff{
+			Name:    after.Name,
+			Before:  before,
+			After:   after,
+			Current: after.Current - before.Current,
+			Total:   after.Total - before.Total,
+		}
+		if d.Interval > 0 {
+			td.Rate = perSecond(td.Current, d.Interval)
+		}
+		d.Trackers = append(d.Trackers, td)
+	}
+	for _, before := range s.readings {
+		if _, ok := later.Reading(before.Name); !ok {
+			d.Removed = append(d.Removed, before)
+		}
+	}
+	return d
+}
+
+// Changed returns the trackers whose current value or total changed
+func (d SnapshotDiff) Changed() []TrackerDiff {
+	changed := []TrackerDiff{}
+	for _, td := range d.Trackers {
+		if td.Current != 0 || td.Total != 0 {
+			changed = append(changed, td)
+		}
+	}
+	return changed
+}
+
+// String reports the changed, added and removed trackers, one per line:
+//
+//	diff over 10s
+//	  bytes: +500 (500/2000 -> 1000/2000) 50/s
+//	+ errors: 1
+//	- retries: 7
+func (d SnapshotDiff) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff over %v\n", d.Interval)
+	for _, td := range d.Changed() {
+		fmt.Fprintf(&b, "  %s: %+d (%s -> %s)", td.Name, td.Current, progressString(td.Before), progressString(td.After))
+		if d.Interval > 0 {
+			fmt.Fprintf(&b, " %d/s", td.Rate)
+		}
+		b.WriteString("\n")
+	}
+	for _, r := range d.Added {
+		fmt.Fprintf(&b, "+ %s: %s\n", r.Name, progressString(r))
+	}
+	for _, r := range d.Removed {
+		fmt.Fprintf(&b, "- %s: %s\n", r.Name, progressString(r))
+	}
+	return b.String()
+}
+
+func progressString(r Reading) string {
+	if r.HasTotal {
+		return fmt.Sprintf("%d/%d", r.Current, r.Total)
+	}
+	return fmt.Sprintf("%d", r.Current)
+}
@@ -0,0 +1,80 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/morrocker/tracker/trackertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot_Diff(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	files := NewCounter()
+	bytes := NewGauge()
+	idle := NewCounter()
+	s := NewSet()
+	s.SetClock(clk)
+	s.AddGauge("bytes", bytes)
+	s.AddCounter("files", files)
+	s.AddCounter("idle", idle)
+	s.AddCounter("retries", NewCounter())
+	bytes.SetTotal(2000)
+	bytes.Current(500)
+	files.Current(2)
+	before := s.Snapshot()
+
+	clk.Advance(10 * time.Second)
+	bytes.Current(500)
+	files.Current(3)
+	s.Remove("retries")
+	s.AddCounter("errors", NewCounter())
+	after := s.Snapshot()
+
+	// snapshots do not follow later changes
+	files.Current(100)
+	r, ok := after.Reading("files")
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), r.Current)
+	assert.Equal(t, epoch, before.Time())
+	assert.Len(t, before.Readings(), 4)
+
+	d := before.Diff(after)
+	assert.Equal(t, 10*time.Second, d.Interval)
+	assert.Equal(t, []string{"bytes", "files", "idle"}, diffNames(d.Trackers))
+	assert.Equal(t, []string{"bytes", "files"}, diffNames(d.Changed()))
+	assert.Equal(t, int64(500), d.Trackers[0].Current)
+	assert.Equal(t, int64(50), d.Trackers[0].Rate)
+	assert.Equal(t, int64(0), d.Trackers[0].Total)
+	assert.Equal(t, []string{"errors"}, names(d.Added))
+	assert.Equal(t, []string{"retries"}, names(d.Removed))
+
+	assert.Equal(t, "diff over 10s\n"+
+		"  bytes: +500 (500/2000 -> 1000/2000) 50/s\n"+
+		"  files: +3 (2 -> 5) 0/s\n"+
+		"+ errors: 0\n"+
+		"- retries: 0\n", d.String())
+}
+
+func TestSnapshot_Stored(t *testing.T) {
+	readings := []Reading{{Name: "files", Current: 4}}
+	old := NewSnapshot(epoch, readings)
+	readings[0].Current = 9
+	r, _ := old.Reading("files")
+	assert.Equal(t, int64(4), r.Current)
+
+	d := old.Diff(NewSnapshot(epoch, []Reading{{Name: "files", Current: 1}}))
+	assert.Equal(t, int64(-3), d.Trackers[0].Current)
+	assert.Equal(t, int64(0), d.Trackers[0].Rate)
+	assert.Equal(t, "diff over 0s\n  files: -3 (4 -> 1)\n", d.String())
+	_, ok := old.Reading("missing")
+	assert.False(t, ok)
+}
+
+func diffNames(diffs []TrackerDiff) []string {
+	n := []string{}
+	for _, d := range diffs {
+		n = append(n, d.Name)
+	}
+	return n
+}
@@ -1,80 +1,409 @@
 package tracker
 
 import (
+	"sync"
+	"sync/atomic"
 	"time"
+)
+
+// ResetPolicy decides what a Speed does with a measurement over which the
+// measured value was reset or went down, as happens across a Reset, a Swap
+// or a rollback
+type ResetPolicy int32
 
-	"github.com/morrocker/benchmark"
+const (
+	// ResetIgnore drops the measurement
+	ResetIgnore ResetPolicy = iota
+	// ResetClamp records the measurement as a zero rate
+	ResetClamp
+	// ResetRestart clears the sample window and starts over
+	ResetRestart
+	// ResetRollback records the decrease in a separate rollback rate
+	ResetRollback
 )
 
-type Speed interface {
+// SpeedOf measures how fast a value of T grows
+type SpeedOf[T Number] interface {
 	SampleSize(uint) uint
 	Reset()
 	StartMeasure() func()
 	StartAutoMeasure(time.Duration)
 	StopAutoMeasure()
-	UnitsFunc(func(int64) string)
-	RawRate() int64
+	SetClock(Clock)
+	UnitsFunc(func(T) string)
+	RawRate() T
 	Rate() string
+	MinRate() T
+	MaxRate() T
+	Variance() float64
+	Samples() []T
+	PercentRate() float64
+	ETA() (time.Duration, bool)
+	ResetPolicy(ResetPolicy)
+	Resets() uint64
+	RollbackRate() T
+	Pause()
+	Resume()
+	Paused() bool
+	ActiveTime() time.Duration
+	WallTime() time.Duration
 }
 
-type speed struct {
-	target    *int64
-	ticker    *time.Ticker
-	rate      benchmark.SingleRate
-	unitsFunc func(int64) string
+// Speed is a SpeedOf int64
+type Speed = SpeedOf[int64]
+
+type speed[T Number] struct {
+	resets    uint64
+	policy    int32
+	target    *T
+	source    progress[T]
+	clock     Clock
+	ticker    Ticker
+	rate      rateEngine[T]
+	rollback  rateEngine[T]
+	unitsFunc atomicUnits[T]
+	pause     *pauser
+	started   time.Time
+	active    time.Time
+	startLock sync.Mutex
+	lock      sync.Mutex
 }
 
 func NewSpeed(g *int64, n uint) Speed {
-	newSpeed := &speed{
-		target: g,
-		rate:   benchmark.NewSingleRate(n),
+	newSpeed := &speed[int64]{
+		target:   g,
+		clock:    realClock,
+		rate:     newSampleRate[int64](n),
+		rollback: newSampleRate[int64](n),
+		pause:    &pauser{},
+	}
+	return newSpeed
+}
+
+// NewGaugeSpeed returns a Speed measuring the current value of g, which
+// also knows the total so it can report PercentRate and ETA. The speed and
+// the gauge share their paused state.
+func NewGaugeSpeed(g Gauge, n uint) Speed {
+	return NewGaugeSpeedOf(g, n)
+}
+
+// NewCounterSpeed returns a Speed measuring the value of c
+func NewCounterSpeed(c Counter, n uint) Speed {
+	return NewCounterSpeedOf(c, n)
+}
+
+// NewGaugeSpeedOf returns a SpeedOf[T] measuring the current value of g
+func NewGaugeSpeedOf[T Number](g GaugeOf[T], n uint) SpeedOf[T] {
+	newSpeed := &speed[T]{
+		source:   gaugeProgress[T]{g},
+		clock:    realClock,
+		rate:     newSampleRate[T](n),
+		rollback: newSampleRate[T](n),
+		pause:    &pauser{},
+	}
+	if p, ok := g.(interface{ pauses() *pauser }); ok {
+		newSpeed.pause = p.pauses()
 	}
 	return newSpeed
 }
 
-func (s *speed) SampleSize(n uint) uint {
+// NewCounterSpeedOf returns a SpeedOf[T] measuring the value of c
+func NewCounterSpeedOf[T Number](c CounterOf[T], n uint) SpeedOf[T] {
+	newSpeed := &speed[T]{
+		source:   counterProgress[T]{c},
+		clock:    realClock,
+		rate:     newSampleRate[T](n),
+		rollback: newSampleRate[T](n),
+		pause:    &pauser{},
+	}
+	return newSpeed
+}
+
+func (s *speed[T]) SampleSize(n uint) uint {
+	if s.rollback != nil {
+		s.rollback.SampleSize(n)
+	}
 	return s.rate.SampleSize(n)
 }
 
-func (s *speed) Reset() {
+func (s *speed[T]) Reset() {
 	s.rate.Reset()
+	if s.rollback != nil {
+		s.rollback.Reset()
+	}
+	atomic.StoreUint64(&s.resets, 0)
 }
 
-func (s *speed) StartMeasure() func() {
-	end := s.rate.MeasureStart(*s.target)
+// StartMeasure records the target's value and returns a function that adds
+// a rate sample for the change since then. Measurements over which the
+// value was reset or went down are handled according to the ResetPolicy. Time spent
+// paused does not count towards the measurement.
+func (s *speed[T]) StartMeasure() func() {
+	return s.startMeasure(s.getClock())
+}
+
+func (s *speed[T]) startMeasure(wall Clock) func() {
+	clock := activeClock{wall, s.pause}
+	s.begin(wall, clock)
+	from, seq, offset := s.snapshot()
+	end := s.rate.MeasureStart(clock, from)
+	// negated so the rollback engine sees the decrease as growth
+	var endRollback func(T)
+	if s.rollback != nil {
+		endRollback = s.rollback.MeasureStart(clock, -from)
+	}
 	return func() {
-		end(*s.target)
+		to, toSeq, toOffset := s.snapshot()
+		if toSeq == seq && to >= from {
+			end(to)
+			return
+		}
+		atomic.AddUint64(&s.resets, 1)
+		// across a reset the offset grows by what the reset removed
+		removed := from - to
+		if toSeq != seq {
+			removed = toOffset - offset
+		}
+		if removed < 0 {
+			removed = 0
+		}
+		switch ResetPolicy(atomic.LoadInt32(&s.policy)) {
+		case ResetClamp:
+			end(from)
+		case ResetRestart:
+			s.rate.Reset()
+		case ResetRollback:
+			if endRollback != nil {
+				endRollback(removed - from)
+			}
+		}
 	}
 }
 
-func (s *speed) StartAutoMeasure(d time.Duration) {
+// StartAutoMeasure adds a sample every d, each covering the time since the
+// previous one. Ticks that only cover paused time add no sample. Calling it
+// while running only changes the period. Periods that are not positive use
+// DefaultInterval.
+func (s *speed[T]) StartAutoMeasure(d time.Duration) {
+	d = validInterval(d)
+	s.lock.Lock()
+	defer s.lock.Unlock()
 	if s.ticker != nil {
 		s.ticker.Reset(d)
 		return
 	}
-	s.ticker = time.NewTicker(d)
+	clock := s.clock
+	end := s.startMeasure(clock)
+	s.ticker = clock.NewTicker(d, func(time.Time) {
+		end()
+		end = s.startMeasure(clock)
+	})
+}
 
-	go func() {
-		for range s.ticker.C {
-			end := s.StartMeasure()
-			time.Sleep(d)
-			end()
-		}
-	}()
+func (s *speed[T]) StopAutoMeasure() {
+	s.lock.Lock()
+	ticker := s.ticker
+	s.ticker = nil
+	s.lock.Unlock()
+	if ticker != nil {
+		ticker.Stop()
+	}
 }
 
-func (s *speed) StopAutoMeasure() {
-	s.ticker.Stop()
+// SetClock sets the clock of the speed, and of the gauge it shares its
+// paused state with
+func (s *speed[T]) SetClock(c Clock) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.clock = c
+	s.pause.setClock(c)
 }
 
-func (s *speed) RawRate() int64 {
+func (s *speed[T]) RawRate() T {
 	return s.rate.AvgRate()
 }
 
-func (s *speed) Rate() string {
-	return s.unitsFunc(s.rate.AvgRate())
+func (s *speed[T]) Rate() string {
+	return s.unitsFunc.format(s.rate.AvgRate())
+}
+
+// MinRate returns the lowest rate in the sample window
+func (s *speed[T]) MinRate() T {
+	return s.rate.MinRate()
+}
+
+// MaxRate returns the highest rate in the sample window
+func (s *speed[T]) MaxRate() T {
+	return s.rate.MaxRate()
+}
+
+// Variance returns the variance of the rates in the sample window
+func (s *speed[T]) Variance() float64 {
+	return s.rate.Variance()
+}
+
+// Samples returns the rates in the sample window, oldest first
+func (s *speed[T]) Samples() []T {
+	return s.rate.Samples()
+}
+
+// PercentRate returns the average rate as percent of the total per second.
+// It is zero for speeds without a known total.
+func (s *speed[T]) PercentRate() float64 {
+	_, total, ok := s.values()
+	if !ok || total <= 0 {
+		return 0
+	}
+	return float64(s.rate.AvgRate()) * 100 / float64(total)
+}
+
+// ETA estimates the active time left to reach the total at the average
+// rate, which leaves paused time out. It returns false when there is no
+// total or no positive rate to go by.
+func (s *speed[T]) ETA() (time.Duration, bool) {
+	current, total, ok := s.values()
+	rate := s.rate.AvgRate()
+	if !ok || total <= 0 || (rate <= 0 && current < total) {
+		return 0, false
+	}
+	return eta(current, total, rate), true
+}
+
+// ResetPolicy sets how measurements over which the value was reset or went
+// down are handled. The default is ResetIgnore.
+func (s *speed[T]) ResetPolicy(p ResetPolicy) {
+	atomic.StoreInt32(&s.policy, int32(p))
+}
+
+// Resets returns how many measurements saw the value reset or go down
+func (s *speed[T]) Resets() uint64 {
+	return atomic.LoadUint64(&s.resets)
+}
+
+// RollbackRate returns the average rate at which the value went down over
+// measurements recorded under ResetRollback
+func (s *speed[T]) RollbackRate() T {
+	if s.rollback == nil {
+		return 0
+	}
+	return s.rollback.AvgRate()
+}
+
+// Pause stops time from counting towards measurements until Resume
+func (s *speed[T]) Pause() {
+	s.pause.pause()
+}
+
+func (s *speed[T]) Resume() {
+	s.pause.resume()
+}
+
+func (s *speed[T]) Paused() bool {
+	return s.pause.isPaused()
+}
+
+// ActiveTime returns the time since the first measurement started, leaving
+// out the time spent paused
+func (s *speed[T]) ActiveTime() time.Duration {
+	wall := s.getClock()
+	s.startLock.Lock()
+	defer s.startLock.Unlock()
+	if s.active.IsZero() {
+		return 0
+	}
+	return activeClock{wall, s.pause}.Now().Sub(s.active)
+}
+
+// WallTime returns the time since the first measurement started
+func (s *speed[T]) WallTime() time.Duration {
+	wall := s.getClock()
+	s.startLock.Lock()
+	defer s.startLock.Unlock()
+	if s.started.IsZero() {
+		return 0
+	}
+	return wall.Now().Sub(s.started)
+}
+
+func (s *speed[T]) UnitsFunc(fn func(T) string) {
+	s.unitsFunc.set(fn)
+}
+
+// begin records when the first measurement started, on the wall clock and
+// on the active clock. It takes startLock rather than lock, as automatic
+// measurements start while lock is held.
+func (s *speed[T]) begin(wall, active Clock) {
+	s.startLock.Lock()
+	defer s.startLock.Unlock()
+	if s.started.IsZero() {
+		s.started = wall.Now()
+		s.active = active.Now()
+	}
+}
+
+func (s *speed[T]) getClock() Clock {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.clock
+}
+
+func (s *speed[T]) values() (T, T, bool) {
+	if s.source != nil {
+		return s.source.progress()
+	}
+	return load(s.target), 0, false
+}
+
+// snapshot returns the current value along with the reset epoch it was read
+// under, so that resets show even when the value has grown past its old
+// level by the end of a measurement
+func (s *speed[T]) snapshot() (T, uint64, T) {
+	if s.source != nil {
+		return s.source.epoch()
+	}
+	return load(s.target), 0, 0
+}
+
+// progress is a value a Speed can measure, optionally growing towards a
+// known total
+type progress[T Number] interface {
+	progress() (current, total T, bounded bool)
+	epoch() (current T, seq uint64, offset T)
+}
+
+// epochOf returns the current value of t with its reset epoch, for trackers
+// that keep one
+func epochOf[T Number](t any, value func() T) (T, uint64, T) {
+	if r, ok := t.(interface{ resets() *resetEpoch[T] }); ok {
+		return r.resets().snapshot(value)
+	}
+	return value(), 0, 0
+}
+
+type gaugeProgress[T Number] struct {
+	g GaugeOf[T]
+}
+
+func (p gaugeProgress[T]) progress() (T, T, bool) {
+	current, total := p.g.RawValues()
+	return current, total, true
+}
+
+func (p gaugeProgress[T]) epoch() (T, uint64, T) {
+	return epochOf(p.g, func() T {
+		current, _ := p.g.RawValues()
+		return current
+	})
+}
+
+type counterProgress[T Number] struct {
+	c CounterOf[T]
+}
+
+func (p counterProgress[T]) progress() (T, T, bool) {
+	return p.c.RawValue(), 0, false
 }
 
-func (s *speed) UnitsFunc(fn func(int64) string) {
-	s.unitsFunc = fn
+func (p counterProgress[T]) epoch() (T, uint64, T) {
+	return epochOf(p.c, p.c.RawValue)
 }
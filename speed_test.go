@@ -7,10 +7,12 @@ import (
 	"testing"
 	"time"
 
-	"github.com/morrocker/benchmark"
+	"github.com/morrocker/tracker/trackertest"
 	"github.com/stretchr/testify/assert"
 )
 
+var epoch = time.Date(2021, 6, 5, 0, 0, 0, 0, time.UTC)
+
 func TestNewSpeed(t *testing.T) {
 	type args struct {
 		g *int64
@@ -28,9 +30,12 @@ func TestNewSpeed(t *testing.T) {
 				g: &n,
 				n: 5,
 			},
-			want: &speed{
-				target: &n,
-				rate:   benchmark.NewSingleRate(5),
+			want: &speed[int64]{
+				target:   &n,
+				clock:    realClock,
+				rate:     newSampleRate[int64](5),
+				rollback: newSampleRate[int64](5),
+				pause:    &pauser{},
 			},
 		},
 	}
@@ -44,7 +49,7 @@ func TestNewSpeed(t *testing.T) {
 
 func Test_speed_SampleSize(t *testing.T) {
 	type fields struct {
-		rate benchmark.SingleRate
+		rate rateEngine[int64]
 	}
 	type args struct {
 		n uint
@@ -58,7 +63,7 @@ func Test_speed_SampleSize(t *testing.T) {
 		{
 			name: "simple test",
 			fields: fields{
-				rate: benchmark.NewSingleRate(10),
+				rate: newSampleRate[int64](10),
 			},
 			args: args{
 				n: 10,
@@ -68,7 +73,7 @@ func Test_speed_SampleSize(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := &speed{
+			s := &speed[int64]{
 				rate: tt.fields.rate,
 			}
 			got := s.SampleSize(tt.args.n)
@@ -80,7 +85,7 @@ func Test_speed_SampleSize(t *testing.T) {
 func Test_speed_StartMeasure(t *testing.T) {
 	type fields struct {
 		target *int64
-		rate   benchmark.SingleRate
+		rate   rateEngine[int64]
 	}
 	type args struct {
 		time   time.Duration
@@ -97,27 +102,41 @@ func Test_speed_StartMeasure(t *testing.T) {
 			name: "simple measure",
 			fields: fields{
 				target: &tgt,
-				rate:   benchmark.NewSingleRate(ss),
+				rate:   newSampleRate[int64](ss),
 			},
 			args: args{
 				time:   1 * time.Second,
 				length: 3,
 			},
 		},
+		{
+			name: "two second measure",
+			fields: fields{
+				target: &tgt,
+				rate:   newSampleRate[int64](ss),
+			},
+			args: args{
+				time:   2 * time.Second,
+				length: 3,
+			},
+		},
 	}
-	sum := int64(0)
 	for _, tt := range tests {
 		tgt = 0
-		r := rand.Int63n(5000)
 		t.Run(tt.name, func(t *testing.T) {
-			s := &speed{
+			clk := trackertest.NewClock(epoch)
+			s := &speed[int64]{
 				target: tt.fields.target,
+				clock:  clk,
 				rate:   tt.fields.rate,
 			}
+			sum := int64(0)
 			for x := 0; x < tt.args.length; x++ {
+				r := rand.Int63n(5000)
 				end := s.StartMeasure()
-				time.Sleep(tt.args.time)
-				tgt += r / int64(tt.args.time)
+				clk.Advance(tt.args.time)
+				tgt += r
+				sum += r / int64(tt.args.time.Seconds())
 				end()
 			}
 			ssz, tot, ln := s.rate.Values()
@@ -131,7 +150,7 @@ func Test_speed_StartMeasure(t *testing.T) {
 func Test_speed_StartStopAutoMeasure(t *testing.T) {
 	type fields struct {
 		target *int64
-		rate   benchmark.SingleRate
+		rate   rateEngine[int64]
 	}
 	type args struct {
 		time   time.Duration
@@ -153,7 +172,7 @@ func Test_speed_StartStopAutoMeasure(t *testing.T) {
 			name: "simple measure",
 			fields: fields{
 				target: &tgt,
-				rate:   benchmark.NewSingleRate(ss),
+				rate:   newSampleRate[int64](ss),
 			},
 			args: args{
 				time:   1000 * time.Millisecond,
@@ -168,12 +187,13 @@ func Test_speed_StartStopAutoMeasure(t *testing.T) {
 	for _, tt := range tests {
 		tgt = 0
 		t.Run(tt.name, func(t *testing.T) {
-			s := &speed{
+			clk := trackertest.NewClock(epoch)
+			s := &speed[int64]{
 				target: tt.fields.target,
+				clock:  clk,
 				rate:   tt.fields.rate,
 			}
 			s.StartAutoMeasure(tt.args.time)
-			time.Sleep(tt.args.time + tt.args.time/2)
 
 			arr := []int64{}
 			for x := 0; x < tt.args.length; x++ {
@@ -184,7 +204,7 @@ func Test_speed_StartStopAutoMeasure(t *testing.T) {
 				} else {
 					arr = append(arr[1:], r)
 				}
-				time.Sleep(tt.args.time)
+				clk.Advance(tt.args.time)
 			}
 			s.StopAutoMeasure()
 			ssz, tot, ln := s.rate.Values()
@@ -194,6 +214,11 @@ func Test_speed_StartStopAutoMeasure(t *testing.T) {
 			assert.Equal(t, tt.want.total, tot)
 			assert.Equal(t, tt.want.length, ln)
 			assert.Equal(t, ssz, ss)
+
+			tgt += 1000
+			clk.Advance(tt.args.time)
+			_, after, _ := s.rate.Values()
+			assert.Equal(t, tot, after)
 		})
 	}
 }
@@ -201,7 +226,7 @@ func Test_speed_StartStopAutoMeasure(t *testing.T) {
 func Test_speed_AutoMeasureReset(t *testing.T) {
 	type fields struct {
 		target *int64
-		rate   benchmark.SingleRate
+		rate   rateEngine[int64]
 	}
 	type args struct {
 		time   time.Duration
@@ -223,7 +248,7 @@ func Test_speed_AutoMeasureReset(t *testing.T) {
 			name: "simple measure",
 			fields: fields{
 				target: &tgt,
-				rate:   benchmark.NewSingleRate(ss),
+				rate:   newSampleRate[int64](ss),
 			},
 			args: args{
 				time:   1000 * time.Millisecond,
@@ -238,16 +263,19 @@ func Test_speed_AutoMeasureReset(t *testing.T) {
 	for _, tt := range tests {
 		tgt = 0
 		t.Run(tt.name, func(t *testing.T) {
-			s := &speed{
+			clk := trackertest.NewClock(epoch)
+			s := &speed[int64]{
 				target: tt.fields.target,
+				clock:  clk,
 				rate:   tt.fields.rate,
 			}
 			s.StartAutoMeasure(tt.args.time)
 			for x := 0; x < 3; x++ {
-				time.Sleep(tt.args.time / 3)
+				clk.Advance(tt.args.time / 3)
 				s.StartAutoMeasure(tt.args.time)
 			}
-			time.Sleep(tt.args.time + tt.args.time/2)
+			_, _, ln := s.rate.Values()
+			assert.Equal(t, 0, ln)
 
 			arr := []int64{}
 			for x := 0; x < tt.args.length; x++ {
@@ -258,7 +286,7 @@ func Test_speed_AutoMeasureReset(t *testing.T) {
 				} else {
 					arr = append(arr[1:], r)
 				}
-				time.Sleep(tt.args.time)
+				clk.Advance(tt.args.time)
 			}
 			s.StopAutoMeasure()
 			ssz, tot, ln := s.rate.Values()
@@ -272,10 +300,23 @@ func Test_speed_AutoMeasureReset(t *testing.T) {
 	}
 }
 
+func Test_speed_AutoMeasureInvalidInterval(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	var tgt int64
+	s := NewSpeed(&tgt, 5)
+	s.SetClock(clk)
+	assert.NotPanics(t, func() { s.StartAutoMeasure(0) })
+	assert.NotPanics(t, func() { s.StartAutoMeasure(-time.Second) })
+	tgt = 10
+	clk.Advance(DefaultInterval)
+	s.StopAutoMeasure()
+	assert.Equal(t, []int64{10}, s.Samples())
+}
+
 func Test_speed_RawRate(t *testing.T) {
 	type fields struct {
 		target *int64
-		rate   benchmark.SingleRate
+		rate   rateEngine[int64]
 	}
 	type args struct {
 		seconds int64
@@ -292,28 +333,41 @@ func Test_speed_RawRate(t *testing.T) {
 			name: "simple measure",
 			fields: fields{
 				target: &tgt,
-				rate:   benchmark.NewSingleRate(ss),
+				rate:   newSampleRate[int64](ss),
 			},
 			args: args{
 				seconds: 1,
 				length:  3,
 			},
 		},
+		{
+			name: "three second measure",
+			fields: fields{
+				target: &tgt,
+				rate:   newSampleRate[int64](ss),
+			},
+			args: args{
+				seconds: 3,
+				length:  4,
+			},
+		},
 	}
 	for _, tt := range tests {
 		tgt = 0
 		t.Run(tt.name, func(t *testing.T) {
 			var r, total int64
-			s := &speed{
+			clk := trackertest.NewClock(epoch)
+			s := &speed[int64]{
 				target: tt.fields.target,
+				clock:  clk,
 				rate:   tt.fields.rate,
 			}
 			for x := 0; x < tt.args.length; x++ {
 				r = rand.Int63n(5000)
 				end := s.StartMeasure()
-				time.Sleep(time.Duration(tt.args.seconds) * time.Second)
-				tgt += r / int64(tt.args.seconds)
-				total += r / int64(tt.args.seconds)
+				clk.Advance(time.Duration(tt.args.seconds) * time.Second)
+				tgt += r
+				total += r / tt.args.seconds
 				end()
 			}
 			got := s.RawRate()
@@ -325,7 +379,7 @@ func Test_speed_RawRate(t *testing.T) {
 func Test_speed_UnitsFunc(t *testing.T) {
 	type fields struct {
 		target *int64
-		rate   benchmark.SingleRate
+		rate   rateEngine[int64]
 	}
 	type args struct {
 		fn func(int64) string
@@ -342,7 +396,7 @@ func Test_speed_UnitsFunc(t *testing.T) {
 			name: "simple function test",
 			fields: fields{
 				target: &tgt,
-				rate:   benchmark.NewSingleRate(ss),
+				rate:   newSampleRate[int64](ss),
 			},
 			args: args{
 				fn: func(x int64) string {
@@ -353,8 +407,10 @@ func Test_speed_UnitsFunc(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := &speed{
+			clk := trackertest.NewClock(epoch)
+			s := &speed[int64]{
 				target: tt.fields.target,
+				clock:  clk,
 				rate:   tt.fields.rate,
 			}
 			s.UnitsFunc(tt.args.fn)
@@ -364,7 +420,7 @@ func Test_speed_UnitsFunc(t *testing.T) {
 				r := rand.Int63n(5000)
 				tgt += r
 				total += r
-				time.Sleep(1 * time.Second)
+				clk.Advance(1 * time.Second)
 				end()
 				got := s.Rate()
 				assert.Equal(t, strconv.Itoa(int(total/int64(x+1))), got)
@@ -376,7 +432,7 @@ func Test_speed_UnitsFunc(t *testing.T) {
 func Test_speed_Reset(t *testing.T) {
 	type fields struct {
 		target *int64
-		rate   benchmark.SingleRate
+		rate   rateEngine[int64]
 	}
 	type args struct {
 		fn func(int64) string
@@ -398,7 +454,7 @@ func Test_speed_Reset(t *testing.T) {
 			name: "simple function test",
 			fields: fields{
 				target: &tgt,
-				rate:   benchmark.NewSingleRate(ss),
+				rate:   newSampleRate[int64](ss),
 			},
 			args: args{
 				fn: func(x int64) string {
@@ -414,8 +470,10 @@ func Test_speed_Reset(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := &speed{
+			clk := trackertest.NewClock(epoch)
+			s := &speed[int64]{
 				target: tt.fields.target,
+				clock:  clk,
 				rate:   tt.fields.rate,
 			}
 			s.UnitsFunc(tt.args.fn)
@@ -424,7 +482,7 @@ func Test_speed_Reset(t *testing.T) {
 			r := rand.Int63n(5000)
 			tgt += r
 			total += r
-			time.Sleep(1 * time.Second)
+			clk.Advance(1 * time.Second)
 			end()
 			s.Reset()
 			ss, tot, ll := s.rate.Values()
@@ -434,3 +492,302 @@ func Test_speed_Reset(t *testing.T) {
 		})
 	}
 }
+
+func Test_speed_GaugeProgress(t *testing.T) {
+	type want struct {
+		rate    int64
+		percent float64
+		eta     time.Duration
+		ok      bool
+	}
+	tests := []struct {
+		name  string
+		total int64
+		steps []int64
+		want  want
+	}{
+		{
+			name:  "steady progress",
+			total: 1000,
+			steps: []int64{100, 100},
+			want: want{
+				rate:    100,
+				percent: 10,
+				eta:     8 * time.Second,
+				ok:      true,
+			},
+		},
+		{
+			name:  "complete",
+			total: 200,
+			steps: []int64{100, 100},
+			want: want{
+				rate:    100,
+				percent: 50,
+				eta:     0,
+				ok:      true,
+			},
+		},
+		{
+			name:  "no progress",
+			total: 200,
+			steps: []int64{0, 0},
+			want: want{
+				ok: false,
+			},
+		},
+		{
+			name:  "no total",
+			total: 0,
+			steps: []int64{50},
+			want: want{
+				rate: 50,
+				ok:   false,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := trackertest.NewClock(epoch)
+			g := NewGauge()
+			g.SetTotal(tt.total)
+			s := NewGaugeSpeed(g, 5)
+			s.SetClock(clk)
+			s.StartAutoMeasure(time.Second)
+			for _, n := range tt.steps {
+				g.Current(n)
+				clk.Advance(time.Second)
+			}
+			s.StopAutoMeasure()
+			assert.Equal(t, tt.want.rate, s.RawRate())
+			assert.Equal(t, tt.want.percent, s.PercentRate())
+			eta, ok := s.ETA()
+			assert.Equal(t, tt.want.eta, eta)
+			assert.Equal(t, tt.want.ok, ok)
+		})
+	}
+}
+
+func Test_speed_GaugeReset(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	g := NewGauge()
+	g.SetTotal(1000)
+	s := NewGaugeSpeed(g, 5)
+	s.SetClock(clk)
+	s.StartAutoMeasure(time.Second)
+	g.Current(300)
+	clk.Advance(time.Second)
+	g.Reset()
+	g.Current(10)
+	clk.Advance(time.Second)
+	g.Current(100)
+	clk.Advance(time.Second)
+	s.StopAutoMeasure()
+	assert.Equal(t, []int64{300, 100}, s.Samples())
+}
+
+func Test_speed_CounterProgress(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	c := NewCounter()
+	s := NewCounterSpeed(c, 5)
+	s.SetClock(clk)
+	end := s.StartMeasure()
+	c.Current(40)
+	clk.Advance(2 * time.Second)
+	end()
+	assert.Equal(t, int64(20), s.RawRate())
+	assert.Equal(t, float64(0), s.PercentRate())
+	_, ok := s.ETA()
+	assert.False(t, ok)
+}
+
+func Test_speed_ResetPolicy(t *testing.T) {
+	type want struct {
+		samples  []int64
+		rollback int64
+		resets   uint64
+	}
+	tests := []struct {
+		name   string
+		policy ResetPolicy
+		want   want
+	}{
+		{
+			name:   "ignore",
+			policy: ResetIgnore,
+			want: want{
+				samples: []int64{100, 50},
+				resets:  1,
+			},
+		},
+		{
+			name:   "clamp",
+			policy: ResetClamp,
+			want: want{
+				samples: []int64{100, 0, 50},
+				resets:  1,
+			},
+		},
+		{
+			name:   "restart",
+			policy: ResetRestart,
+			want: want{
+				samples: []int64{50},
+				resets:  1,
+			},
+		},
+		{
+			name:   "rollback",
+			policy: ResetRollback,
+			want: want{
+				samples:  []int64{100, 50},
+				rollback: 30,
+				resets:   1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tgt int64
+			clk := trackertest.NewClock(epoch)
+			s := NewSpeed(&tgt, 5)
+			s.SetClock(clk)
+			s.ResetPolicy(tt.policy)
+			s.StartAutoMeasure(time.Second)
+			for _, n := range []int64{100, -30, 50} {
+				tgt += n
+				clk.Advance(time.Second)
+			}
+			s.StopAutoMeasure()
+			assert.Equal(t, tt.want.samples, s.Samples())
+			assert.Equal(t, tt.want.rollback, s.RollbackRate())
+			assert.Equal(t, tt.want.resets, s.Resets())
+			assert.GreaterOrEqual(t, s.RawRate(), int64(0))
+
+			s.Reset()
+			assert.Equal(t, uint64(0), s.Resets())
+			assert.Equal(t, int64(0), s.RollbackRate())
+		})
+	}
+}
+
+func Test_speed_ResetWithinMeasure(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy ResetPolicy
+		reset  func(Counter)
+		want   []int64
+	}{
+		{
+			name:   "reset ignored",
+			policy: ResetIgnore,
+			reset:  func(c Counter) { c.Reset() },
+			want:   []int64{100},
+		},
+		{
+			name:   "reset clamped",
+			policy: ResetClamp,
+			reset:  func(c Counter) { c.Reset() },
+			want:   []int64{100, 0},
+		},
+		{
+			name:   "swap",
+			policy: ResetRollback,
+			reset:  func(c Counter) { c.Swap(20) },
+			want:   []int64{100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := trackertest.NewClock(epoch)
+			c := NewCounter()
+			s := NewCounterSpeed(c, 5)
+			s.SetClock(clk)
+			s.ResetPolicy(tt.policy)
+			s.StartAutoMeasure(time.Second)
+			c.Current(100)
+			clk.Advance(time.Second)
+			tt.reset(c)
+			c.Current(150)
+			clk.Advance(time.Second)
+			s.StopAutoMeasure()
+			assert.Equal(t, tt.want, s.Samples())
+			assert.Equal(t, uint64(1), s.Resets())
+		})
+	}
+}
+
+func Test_speed_GaugeResetRollback(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	g := NewGauge()
+	s := NewGaugeSpeed(g, 5)
+	s.SetClock(clk)
+	s.ResetPolicy(ResetRollback)
+	g.SetCurrent(100)
+	end := s.StartMeasure()
+	g.Reset()
+	g.Current(150)
+	clk.Advance(time.Second)
+	end()
+	assert.Equal(t, uint64(1), s.Resets())
+	assert.Equal(t, int64(100), s.RollbackRate())
+	assert.Equal(t, []int64{}, s.Samples())
+}
+
+func Test_speed_Pause(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	g := NewGauge()
+	g.SetTotal(1000)
+	s := NewGaugeSpeed(g, 10)
+	s.SetClock(clk)
+	s.StartAutoMeasure(time.Second)
+	for i := 0; i < 3; i++ {
+		g.Current(10)
+		clk.Advance(time.Second)
+	}
+
+	// ticks while paused add no zero samples
+	g.Pause()
+	assert.True(t, s.Paused())
+	clk.Advance(5 * time.Second)
+	g.Resume()
+
+	// the ticks around a pause only count their active half seconds
+	g.Current(5)
+	clk.Advance(500 * time.Millisecond)
+	s.Pause()
+	clk.Advance(2 * time.Second)
+	s.Resume()
+	g.Current(5)
+	clk.Advance(500 * time.Millisecond)
+	s.StopAutoMeasure()
+
+	assert.Equal(t, []int64{10, 10, 10, 10, 10}, s.Samples())
+	assert.Equal(t, 4*time.Second, s.ActiveTime())
+	assert.Equal(t, 11*time.Second, s.WallTime())
+	eta, ok := s.ETA()
+	assert.True(t, ok)
+	assert.Equal(t, 96*time.Second, eta)
+}
+
+func Test_speed_PauseManual(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	c := NewCounter()
+	s := NewCounterSpeed(c, 5)
+	s.SetClock(clk)
+	assert.Equal(t, time.Duration(0), s.ActiveTime())
+	end := s.StartMeasure()
+	c.Current(20)
+	clk.Advance(time.Second)
+	s.Pause()
+	s.Pause()
+	clk.Advance(3 * time.Second)
+	s.Resume()
+	c.Current(20)
+	clk.Advance(time.Second)
+	end()
+	assert.Equal(t, int64(20), s.RawRate())
+	assert.False(t, s.Paused())
+	assert.Equal(t, 2*time.Second, s.ActiveTime())
+	assert.Equal(t, 5*time.Second, s.WallTime())
+}
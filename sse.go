@@ -0,0 +1,156 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventStream is an http.Handler that streams a Set as Server-Sent Events.
+// Each client first gets a "snapshot" event with every Reading, then
+// "delta" events carrying only the Readings that changed and the names of
+// trackers that went away, at most once per interval. A "prefix" query
+// parameter limits the stream to trackers whose name starts with it.
+//
+// Readings are taken from the trackers' atomics, so a slow client never
+// holds up writers; updates for it coalesce until it catches up.
+type EventStream interface {
+	http.Handler
+	Interval(time.Duration)
+	SetClock(Clock)
+}
+
+type eventStream struct {
+	set      Set
+	interval time.Duration
+	clock    Clock
+	lock     sync.Mutex
+}
+
+// NewEventStream returns an EventStream over s sending at most one event
+// every d. Intervals that are not positive use DefaultInterval.
+func NewEventStream(s Set, d time.Duration) EventStream {
+	newStream := &eventStream{
+		set:      s,
+		interval: validInterval(d),
+		clock:    realClock,
+	}
+	return newStream
+}
+
+// Interval sets the minimum time between events for new clients. Intervals
+// that are not positive use DefaultInterval.
+func (e *eventStream) Interval(d time.Duration) {
+	d = validInterval(d)
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.interval = d
+}
+
+func (e *eventStream) SetClock(c Clock) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.clock = c
+}
+
+type deltaJSON struct {
+	Trackers []Reading `json:"trackers"`
+	Removed  []string  `json:"removed,omitempty"`
+}
+
+func (e *eventStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	prefix := r.URL.Query().Get("prefix")
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	e.lock.Lock()
+	clock, interval := e.clock, e.interval
+	e.lock.Unlock()
+	notify := make(chan struct{}, 1)
+	ticker := clock.NewTicker(interval, func(time.Time) {
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	})
+	defer ticker.Stop()
+
+	last := e.read(prefix)
+	if err := writeEvent(w, "snapshot", snapshotJSON{Trackers: last}); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-notify:
+		}
+		now := e.read(prefix)
+		delta := diffReadings(last, now)
+		if len(delta.Trackers) == 0 && len(delta.Removed) == 0 {
+			continue
+		}
+		if err := writeEvent(w, "delta", delta); err != nil {
+			return
+		}
+		flusher.Flush()
+		last = now
+	}
+}
+
+func (e *eventStream) read(prefix string) []Reading {
+	readings := e.set.Read()
+	if prefix == "" {
+		return readings
+	}
+	filtered := readings[:0]
+	for _, rd := range readings {
+		if strings.HasPrefix(rd.Name, prefix) {
+			filtered = append(filtered, rd)
+		}
+	}
+	return filtered
+}
+
+// diffReadings returns the readings in now that are new or differ from
+// before, and the names that are no longer present
+func diffReadings(before, now []Reading) deltaJSON {
+	old := make(map[string]Reading, len(before))
+	for _, rd := range before {
+		old[rd.Name] = rd
+	}
+	delta := deltaJSON{Trackers: []Reading{}}
+	for _, rd := range now {
+		if prev, ok := old[rd.Name]; !ok || prev != rd {
+			delta.Trackers = append(delta.Trackers, rd)
+		}
+		delete(old, rd.Name)
+	}
+	for _, rd := range before {
+		if _, ok := old[rd.Name]; ok {
+			delta.Removed = append(delta.Removed, rd.Name)
+		}
+	}
+	return delta
+}
+
+func writeEvent(w io.Writer, event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -0,0 +1,142 @@
+package tracker
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morrocker/tracker/trackertest"
+	"github.com/stretchr/testify/assert"
+)
+
+type sseEvent struct {
+	name  string
+	delta deltaJSON
+}
+
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	var ev sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return ev
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			return ev
+		case strings.HasPrefix(line, "event: "):
+			ev.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev.delta))
+		}
+	}
+}
+
+func names(readings []Reading) []string {
+	n := []string{}
+	for _, rd := range readings {
+		n = append(n, rd.Name)
+	}
+	return n
+}
+
+func TestEventStream(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	files := NewCounter()
+	bytes := NewGauge()
+	other := NewCounter()
+	s := NewSet()
+	s.AddCounter("job.files", files)
+	s.AddGauge("job.bytes", bytes)
+	s.AddCounter("other", other)
+	es := NewEventStream(s, time.Second)
+	es.SetClock(clk)
+	srv := httptest.NewServer(es)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?prefix=job.")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	r := bufio.NewReader(resp.Body)
+
+	ev := readEvent(t, r)
+	assert.Equal(t, "snapshot", ev.name)
+	assert.Equal(t, []string{"job.files", "job.bytes"}, names(ev.delta.Trackers))
+
+	other.Current(5)
+	files.Current(3)
+	files.Current(4)
+	clk.Advance(time.Second)
+	ev = readEvent(t, r)
+	assert.Equal(t, "delta", ev.name)
+	assert.Equal(t, []string{"job.files"}, names(ev.delta.Trackers))
+	assert.Equal(t, int64(7), ev.delta.Trackers[0].Current)
+
+	s.Remove("job.files")
+	s.AddCounter("job.errors", NewCounter())
+	clk.Advance(time.Second)
+	ev = readEvent(t, r)
+	assert.Equal(t, []string{"job.errors"}, names(ev.delta.Trackers))
+	assert.Equal(t, []string{"job.files"}, ev.delta.Removed)
+}
+
+func TestEventStream_InvalidInterval(t *testing.T) {
+	s := NewSet()
+	s.AddCounter("files", NewCounter())
+	es := NewEventStream(s, 0)
+	srv := httptest.NewServer(es)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	ev := readEvent(t, bufio.NewReader(resp.Body))
+	assert.Equal(t, "snapshot", ev.name)
+
+	es.Interval(-time.Second)
+	assert.Equal(t, DefaultInterval, es.(*eventStream).interval)
+}
+
+func Test_diffReadings(t *testing.T) {
+	before := []Reading{{Name: "a", Current: 1}, {Name: "b", Current: 2}}
+	tests := []struct {
+		name    string
+		now     []Reading
+		changed []string
+		removed []string
+	}{
+		{
+			name:    "unchanged",
+			now:     before,
+			changed: []string{},
+		},
+		{
+			name:    "one changed",
+			now:     []Reading{{Name: "a", Current: 1}, {Name: "b", Current: 3}},
+			changed: []string{"b"},
+		},
+		{
+			name:    "added and removed",
+			now:     []Reading{{Name: "b", Current: 2}, {Name: "c"}},
+			changed: []string{"c"},
+			removed: []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffReadings(before, tt.now)
+			assert.Equal(t, tt.changed, names(got.Trackers))
+			assert.Equal(t, tt.removed, got.Removed)
+		})
+	}
+}
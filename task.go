@@ -0,0 +1,243 @@
+package tracker
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State is the lifecycle state of a Task
+type State int32
+
+const (
+	// Pending tasks have not started yet
+	Pending State = iota
+	// Running tasks are making progress
+	Running
+	// Paused tasks have started but are not making progress
+	Paused
+	// Succeeded tasks finished without error
+	Succeeded
+	// Failed tasks finished with an error
+	Failed
+	// Cancelled tasks were stopped before they finished
+	Cancelled
+)
+
+var stateNames = [...]string{"pending", "running", "paused", "succeeded", "failed", "cancelled"}
+
+func (s State) String() string {
+	if s < 0 || int(s) >= len(stateNames) {
+		return fmt.Sprintf("State(%d)", int32(s))
+	}
+	return stateNames[s]
+}
+
+// Done reports whether s is a final state
+func (s State) Done() bool {
+	return s >= Succeeded
+}
+
+// TaskCounts holds the number of tasks in each State
+type TaskCounts map[State]int
+
+// String lists the non zero counts in lifecycle order, as in
+// "3 running, 1 failed"
+func (c TaskCounts) String() string {
+	parts := []string{}
+	for s := range stateNames {
+		if n := c[State(s)]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, State(s)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Task follows a job through its lifecycle, with a Gauge for its progress
+// and child tasks for its parts
+type Task interface {
+	Name() string
+	Gauge() Gauge
+	SetClock(Clock)
+	State() State
+	Start() error
+	Pause() error
+	Resume() error
+	Succeed() error
+	Fail(err error) error
+	Cancel() error
+	Err() error
+	Started() time.Time
+	Ended() time.Time
+	AddChild(name string, g Gauge) Task
+	Children() []Task
+	Counts() TaskCounts
+}
+
+type task struct {
+	name     string
+	gauge    Gauge
+	clock    Clock
+	state    State
+	err      error
+	started  time.Time
+	ended    time.Time
+	children []Task
+	lock     sync.RWMutex
+}
+
+// NewTask returns a Pending task tracking its progress with g. A nil g gets
+// a new Gauge.
+func NewTask(name string, g Gauge) Task {
+	if g == nil {
+		g = NewGauge()
+	}
+	newTask := &task{
+		name:  name,
+		gauge: g,
+		clock: realClock,
+	}
+	return newTask
+}
+
+func (t *task) Name() string {
+	return t.name
+}
+
+func (t *task) Gauge() Gauge {
+	return t.gauge
+}
+
+// SetClock sets the clock used for timestamps of this task and of the
+// children added after it
+func (t *task) SetClock(c Clock) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.clock = c
+}
+
+func (t *task) State() State {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.state
+}
+
+// Start moves a Pending task to Running
+func (t *task) Start() error {
+	return t.transition(Running, nil, Pending)
+}
+
+// Pause moves a Running task to Paused and pauses its gauge
+func (t *task) Pause() error {
+	return t.transition(Paused, nil, Running)
+}
+
+// Resume moves a Paused task back to Running and resumes its gauge
+func (t *task) Resume() error {
+	return t.transition(Running, nil, Paused)
+}
+
+// Succeed finishes a Running or Paused task
+func (t *task) Succeed() error {
+	return t.transition(Succeeded, nil, Running, Paused)
+}
+
+// Fail finishes an unfinished task, keeping err as the reason
+func (t *task) Fail(err error) error {
+	return t.transition(Failed, err, Pending, Running, Paused)
+}
+
+// Cancel finishes an unfinished task and cancels its unfinished children
+func (t *task) Cancel() error {
+	if err := t.transition(Cancelled, nil, Pending, Running, Paused); err != nil {
+		return err
+	}
+	for _, child := range t.Children() {
+		if !child.State().Done() {
+			child.Cancel()
+		}
+	}
+	return nil
+}
+
+// transition moves the task to next if it is in one of from. Errors wrap
+// ErrTransition.
+func (t *task) transition(next State, reason error, from ...State) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	allowed := false
+	for _, s := range from {
+		allowed = allowed || t.state == s
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s to %s", ErrTransition, t.state, next)
+	}
+	now := t.clock.Now()
+	if next == Running && t.started.IsZero() {
+		t.started = now
+	}
+	if next.Done() {
+		t.ended = now
+	}
+	switch {
+	case next == Paused:
+		t.gauge.Pause()
+	case t.state == Paused:
+		t.gauge.Resume()
+	}
+	t.state = next
+	t.err = reason
+	return nil
+}
+
+// Err returns the reason given to Fail
+func (t *task) Err() error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.err
+}
+
+// Started returns when the task first ran, or the zero time
+func (t *task) Started() time.Time {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.started
+}
+
+// Ended returns when the task finished, or the zero time
+func (t *task) Ended() time.Time {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.ended
+}
+
+// AddChild adds a Pending child task sharing this task's clock
+func (t *task) AddChild(name string, g Gauge) Task {
+	child := NewTask(name, g)
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	child.SetClock(t.clock)
+	t.children = append(t.children, child)
+	return child
+}
+
+func (t *task) Children() []Task {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	children := make([]Task, len(t.children))
+	copy(children, t.children)
+	return children
+}
+
+// Counts returns the number of descendants of the task in each state
+func (t *task) Counts() TaskCounts {
+	counts := TaskCounts{}
+	for _, child := range t.Children() {
+		counts[child.State()]++
+		for s, n := range child.Counts() {
+			counts[s] += n
+		}
+	}
+	return counts
+}
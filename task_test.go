@@ -0,0 +1,146 @@
+package tracker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/morrocker/tracker/trackertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTask_Transitions(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps func(Task) error
+		want  State
+		err   bool
+	}{
+		{
+			name:  "start",
+			steps: func(tk Task) error { return tk.Start() },
+			want:  Running,
+		},
+		{
+			name: "pause and resume",
+			steps: func(tk Task) error {
+				tk.Start()
+				tk.Pause()
+				return tk.Resume()
+			},
+			want: Running,
+		},
+		{
+			name: "succeed while paused",
+			steps: func(tk Task) error {
+				tk.Start()
+				tk.Pause()
+				return tk.Succeed()
+			},
+			want: Succeeded,
+		},
+		{
+			name:  "succeed before start",
+			steps: func(tk Task) error { return tk.Succeed() },
+			want:  Pending,
+			err:   true,
+		},
+		{
+			name:  "pause before start",
+			steps: func(tk Task) error { return tk.Pause() },
+			want:  Pending,
+			err:   true,
+		},
+		{
+			name:  "cancel pending",
+			steps: func(tk Task) error { return tk.Cancel() },
+			want:  Cancelled,
+		},
+		{
+			name: "restart finished",
+			steps: func(tk Task) error {
+				tk.Start()
+				tk.Succeed()
+				return tk.Start()
+			},
+			want: Succeeded,
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tk := NewTask("copy", nil)
+			err := tt.steps(tk)
+			assert.Equal(t, tt.want, tk.State())
+			if tt.err {
+				assert.True(t, errors.Is(err, ErrTransition))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTask_Timestamps(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	tk := NewTask("copy", nil)
+	tk.SetClock(clk)
+	assert.True(t, tk.Started().IsZero())
+
+	clk.Advance(time.Second)
+	tk.Start()
+	clk.Advance(time.Second)
+	tk.Pause()
+	tk.Resume()
+	clk.Advance(time.Second)
+	reason := errors.New("disk full")
+	assert.NoError(t, tk.Fail(reason))
+
+	assert.Equal(t, epoch.Add(time.Second), tk.Started())
+	assert.Equal(t, epoch.Add(3*time.Second), tk.Ended())
+	assert.Equal(t, reason, tk.Err())
+}
+
+func TestTask_PauseGauge(t *testing.T) {
+	tk := NewTask("copy", nil)
+	assert.NoError(t, tk.Start())
+	assert.NoError(t, tk.Pause())
+	assert.True(t, tk.Gauge().Paused())
+	assert.NoError(t, tk.Resume())
+	assert.False(t, tk.Gauge().Paused())
+
+	assert.NoError(t, tk.Pause())
+	assert.NoError(t, tk.Cancel())
+	assert.False(t, tk.Gauge().Paused())
+}
+
+func TestTask_Children(t *testing.T) {
+	g := NewGauge()
+	job := NewTask("job", g)
+	assert.Equal(t, g, job.Gauge())
+
+	files := []Task{}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		files = append(files, job.AddChild(name, nil))
+	}
+	part := files[0].AddChild("a.1", nil)
+	for _, f := range files[:4] {
+		f.Start()
+	}
+	part.Start()
+	files[3].Fail(errors.New("permission denied"))
+
+	assert.Len(t, job.Children(), 5)
+	assert.Equal(t, TaskCounts{Pending: 1, Running: 4, Failed: 1}, job.Counts())
+	assert.Equal(t, "1 pending, 4 running, 1 failed", job.Counts().String())
+
+	assert.NoError(t, job.Cancel())
+	assert.Equal(t, "1 failed, 5 cancelled", job.Counts().String())
+}
+
+func TestState_String(t *testing.T) {
+	assert.Equal(t, "paused", Paused.String())
+	assert.Equal(t, "State(9)", State(9).String())
+	assert.True(t, Cancelled.Done())
+	assert.False(t, Paused.Done())
+}
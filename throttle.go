@@ -0,0 +1,199 @@
+package tracker
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/morrocker/tracker/internal/clock"
+)
+
+// Tracker is a tracker whose current value can be advanced, such as a
+// Counter or a Gauge
+type Tracker interface {
+	Current(n int64) (int64, error)
+}
+
+// Throttle is a token bucket that holds a stream to limit units per second,
+// allowing bursts of up to burst units. Readers and writers it wraps record
+// what they move on a Tracker, so a Speed over that tracker observes the
+// throttled rate.
+type Throttle interface {
+	SetLimit(int64)
+	SetBurst(int64)
+	SetClock(Clock)
+	Wait(n int64)
+	Reader(io.Reader, Tracker) io.Reader
+	Writer(io.Writer, Tracker) io.Writer
+	Waited() Counter
+}
+
+type throttle struct {
+	limit  int64
+	burst  int64
+	tokens float64
+	last   time.Time
+	clock  Clock
+	waited Counter
+	lock   sync.Mutex
+}
+
+// NewThrottle returns a Throttle allowing limit units per second. A limit
+// of zero or less disables throttling and a burst of zero or less allows one
+// second worth of units.
+func NewThrottle(limit, burst int64) Throttle {
+	waited := NewMonotonicCounter()
+	waited.UnitsFunc(UnitsFor[int64](Duration))
+	newThrottle := &throttle{
+		limit:  limit,
+		burst:  burst,
+		clock:  realClock,
+		waited: waited,
+	}
+	newThrottle.tokens = float64(newThrottle.size())
+	return newThrottle
+}
+
+// SetLimit changes the limit. Waits already in progress keep the delay
+// worked out under the old limit.
+func (t *throttle) SetLimit(n int64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.refill()
+	t.limit = n
+}
+
+func (t *throttle) SetBurst(n int64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.refill()
+	t.burst = n
+}
+
+func (t *throttle) SetClock(c Clock) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.clock = c
+	t.last = time.Time{}
+}
+
+// Wait takes n tokens from the bucket, blocking until the bucket has
+// refilled enough to cover them
+func (t *throttle) Wait(n int64) {
+	t.lock.Lock()
+	if t.limit <= 0 {
+		t.lock.Unlock()
+		return
+	}
+	t.refill()
+	t.tokens -= float64(n)
+	var d time.Duration
+	if t.tokens < 0 {
+		d = time.Duration(-t.tokens / float64(t.limit) * float64(time.Second))
+	}
+	c := t.clock
+	t.lock.Unlock()
+	if d > 0 {
+		clock.Sleep(c, d)
+		t.waited.Current(int64(d))
+	}
+}
+
+// Reader returns a reader that reads from r no faster than the limit and
+// adds what it reads to tr, which may be nil
+func (t *throttle) Reader(r io.Reader, tr Tracker) io.Reader {
+	return &throttledReader{r: r, t: t, tr: tr}
+}
+
+// Writer returns a writer that writes to w no faster than the limit and
+// adds what it writes to tr, which may be nil
+func (t *throttle) Writer(w io.Writer, tr Tracker) io.Writer {
+	return &throttledWriter{w: w, t: t, tr: tr}
+}
+
+// Waited returns a counter of the time spent waiting, in nanoseconds
+func (t *throttle) Waited() Counter {
+	return t.waited
+}
+
+// size returns the bucket size, which is also the largest chunk a wrapped
+// reader or writer moves at once
+func (t *throttle) size() int64 {
+	if t.burst > 0 {
+		return t.burst
+	}
+	if t.limit > 0 {
+		return t.limit
+	}
+	return 1
+}
+
+func (t *throttle) chunk() int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.limit <= 0 {
+		return 0
+	}
+	return t.size()
+}
+
+func (t *throttle) refill() {
+	now := t.clock.Now()
+	if !t.last.IsZero() && t.limit > 0 {
+		t.tokens += now.Sub(t.last).Seconds() * float64(t.limit)
+	}
+	if max := float64(t.size()); t.tokens > max {
+		t.tokens = max
+	}
+	t.last = now
+}
+
+type throttledReader struct {
+	r  io.Reader
+	t  *throttle
+	tr Tracker
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if c := r.t.chunk(); c > 0 && int64(len(p)) > c {
+		p = p[:c]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.t.Wait(int64(n))
+		if r.tr != nil {
+			r.tr.Current(int64(n))
+		}
+	}
+	return n, err
+}
+
+type throttledWriter struct {
+	w  io.Writer
+	t  *throttle
+	tr Tracker
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if c := w.t.chunk(); c > 0 && int64(len(chunk)) > c {
+			chunk = chunk[:c]
+		}
+		w.t.Wait(int64(len(chunk)))
+		n, err := w.w.Write(chunk)
+		written += n
+		if n > 0 && w.tr != nil {
+			w.tr.Current(int64(n))
+		}
+		if err != nil {
+			return written, err
+		}
+		if n < len(chunk) {
+			return written, io.ErrShortWrite
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
@@ -0,0 +1,153 @@
+package tracker
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morrocker/tracker/trackertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottle_Wait(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int64
+		burst int64
+		take  []int64
+		want  time.Duration
+	}{
+		{
+			name:  "within burst",
+			limit: 100,
+			burst: 50,
+			take:  []int64{20, 30},
+			want:  0,
+		},
+		{
+			name:  "past burst",
+			limit: 100,
+			burst: 50,
+			take:  []int64{50, 25},
+			want:  250 * time.Millisecond,
+		},
+		{
+			name:  "default burst is one second",
+			limit: 100,
+			take:  []int64{100, 200},
+			want:  2 * time.Second,
+		},
+		{
+			name:  "unlimited",
+			limit: 0,
+			take:  []int64{1 << 40},
+			want:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := trackertest.NewClock(epoch)
+			th := NewThrottle(tt.limit, tt.burst)
+			th.SetClock(clk)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for _, n := range tt.take {
+					th.Wait(n)
+				}
+			}()
+			if tt.want > 0 {
+				clk.BlockUntil(1)
+				clk.Advance(tt.want - time.Nanosecond)
+				select {
+				case <-done:
+					t.Fatal("wait returned early")
+				default:
+				}
+				clk.Advance(time.Nanosecond)
+			}
+			<-done
+			assert.Equal(t, int64(tt.want), th.Waited().RawValue())
+		})
+	}
+}
+
+func TestThrottle_Writer(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	th := NewThrottle(1000, 0)
+	th.SetClock(clk)
+	g := NewGauge()
+	g.SetTotal(3000)
+	var out bytes.Buffer
+	w := th.Writer(&out, g)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		n, err := w.Write(bytes.Repeat([]byte("x"), 3000))
+		assert.NoError(t, err)
+		assert.Equal(t, 3000, n)
+	}()
+	for x := 1; x <= 2; x++ {
+		clk.BlockUntil(1)
+		curr, _ := g.RawValues()
+		assert.Equal(t, int64(x*1000), curr)
+		clk.Advance(time.Second)
+	}
+	<-done
+	curr, _ := g.RawValues()
+	assert.Equal(t, int64(3000), curr)
+	assert.Equal(t, 3000, out.Len())
+	assert.Equal(t, "2s", th.Waited().Value())
+}
+
+func TestThrottle_Reader(t *testing.T) {
+	clk := trackertest.NewClock(epoch)
+	th := NewThrottle(10, 4)
+	th.SetClock(clk)
+	c := NewCounter()
+	r := th.Reader(strings.NewReader("0123456789"), c)
+
+	p := make([]byte, 10)
+	n, err := r.Read(p)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, int64(4), c.RawValue())
+
+	th.SetLimit(0)
+	b, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "456789", string(b))
+	assert.Equal(t, int64(10), c.RawValue())
+	assert.Equal(t, int64(0), th.Waited().RawValue())
+}
+
+// tickClock hides the Sleep method of the clock it wraps
+type tickClock struct {
+	Clock
+}
+
+func TestThrottle_WaitWithoutSleeper(t *testing.T) {
+	th := NewThrottle(1000, 0)
+	th.SetClock(tickClock{realClock})
+	start := time.Now()
+	th.Wait(1000)
+	th.Wait(50)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.InDelta(t, int64(50*time.Millisecond), th.Waited().RawValue(), float64(10*time.Millisecond))
+}
+
+type stuckWriter struct{}
+
+func (stuckWriter) Write(p []byte) (int, error) {
+	return 0, nil
+}
+
+func TestThrottle_WriterShortWrite(t *testing.T) {
+	th := NewThrottle(0, 0)
+	n, err := th.Writer(stuckWriter{}, nil).Write([]byte("data"))
+	assert.Equal(t, 0, n)
+	assert.ErrorIs(t, err, io.ErrShortWrite)
+}
@@ -0,0 +1,162 @@
+// Package trackertest provides helpers for testing code that uses tracker.
+package trackertest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/morrocker/tracker/internal/clock"
+)
+
+// Clock is a fake tracker.Clock whose time only moves when Advance is
+// called. Ticker functions run synchronously inside Advance, so their
+// effects are visible as soon as it returns, and goroutines in Sleep are
+// released once the clock passes their wake up time.
+type Clock struct {
+	now      time.Time
+	tickers  []*ticker
+	sleepers []*sleeper
+	lock     sync.Mutex
+	cond     *sync.Cond
+}
+
+// NewClock returns a fake clock set to t
+func NewClock(t time.Time) *Clock {
+	newClock := &Clock{
+		now: t,
+	}
+	newClock.cond = sync.NewCond(&newClock.lock)
+	return newClock
+}
+
+func (c *Clock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// Sleep blocks until Advance moves the clock d past the time of the call
+func (c *Clock) Sleep(d time.Duration) {
+	c.lock.Lock()
+	if d <= 0 {
+		c.lock.Unlock()
+		return
+	}
+	s := &sleeper{until: c.now.Add(d), done: make(chan struct{})}
+	c.sleepers = append(c.sleepers, s)
+	c.cond.Broadcast()
+	c.lock.Unlock()
+	<-s.done
+}
+
+// BlockUntil waits until at least n goroutines are blocked in Sleep, so a
+// test can advance the clock knowing they are waiting on it
+func (c *Clock) BlockUntil(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for len(c.sleepers) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *Clock) NewTicker(d time.Duration, f func(time.Time)) clock.Ticker {
+	if d <= 0 {
+		panic("trackertest: non-positive interval for NewTicker")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	t := &ticker{
+		clock:  c,
+		f:      f,
+		period: d,
+		next:   c.now.Add(d),
+	}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance moves the clock forward by d, running every ticker function that
+// falls due on the way in chronological order
+func (c *Clock) Advance(d time.Duration) {
+	c.lock.Lock()
+	target := c.now.Add(d)
+	c.lock.Unlock()
+	for {
+		c.lock.Lock()
+		t := c.due(target)
+		if t == nil {
+			c.now = target
+			c.wake()
+			c.lock.Unlock()
+			return
+		}
+		c.now = t.next
+		c.wake()
+		t.next = t.next.Add(t.period)
+		now := c.now
+		c.lock.Unlock()
+		t.f(now)
+	}
+}
+
+// wake releases the sleepers whose time has come
+func (c *Clock) wake() {
+	waiting := c.sleepers[:0]
+	for _, s := range c.sleepers {
+		if s.until.After(c.now) {
+			waiting = append(waiting, s)
+			continue
+		}
+		close(s.done)
+	}
+	c.sleepers = waiting
+}
+
+func (c *Clock) due(target time.Time) *ticker {
+	var first *ticker
+	for _, t := range c.tickers {
+		if t.next.After(target) {
+			continue
+		}
+		if first == nil || t.next.Before(first.next) {
+			first = t
+		}
+	}
+	return first
+}
+
+func (c *Clock) remove(t *ticker) {
+	for i, x := range c.tickers {
+		if x == t {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+type sleeper struct {
+	until time.Time
+	done  chan struct{}
+}
+
+type ticker struct {
+	clock  *Clock
+	f      func(time.Time)
+	period time.Duration
+	next   time.Time
+}
+
+func (t *ticker) Reset(d time.Duration) {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+	t.clock.remove(t)
+	t.period = d
+	t.next = t.clock.now.Add(d)
+	t.clock.tickers = append(t.clock.tickers, t)
+}
+
+func (t *ticker) Stop() {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+	t.clock.remove(t)
+}
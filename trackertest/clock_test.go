@@ -0,0 +1,92 @@
+package trackertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock_Advance(t *testing.T) {
+	start := time.Date(2021, 6, 5, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		periods []time.Duration
+		advance time.Duration
+		want    []time.Duration
+	}{
+		{
+			name:    "single ticker",
+			periods: []time.Duration{time.Second},
+			advance: 3500 * time.Millisecond,
+			want:    []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		},
+		{
+			name:    "interleaved tickers",
+			periods: []time.Duration{2 * time.Second, 3 * time.Second},
+			advance: 6 * time.Second,
+			want: []time.Duration{
+				2 * time.Second, 3 * time.Second, 4 * time.Second,
+				6 * time.Second, 6 * time.Second,
+			},
+		},
+		{
+			name:    "nothing due",
+			periods: []time.Duration{time.Minute},
+			advance: time.Second,
+			want:    []time.Duration{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClock(start)
+			got := []time.Duration{}
+			for _, p := range tt.periods {
+				c.NewTicker(p, func(now time.Time) {
+					assert.Equal(t, now, c.Now())
+					got = append(got, now.Sub(start))
+				})
+			}
+			c.Advance(tt.advance)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, start.Add(tt.advance), c.Now())
+		})
+	}
+}
+
+func TestClock_ResetStop(t *testing.T) {
+	start := time.Date(2021, 6, 5, 0, 0, 0, 0, time.UTC)
+	c := NewClock(start)
+	n := 0
+	tk := c.NewTicker(time.Second, func(time.Time) { n++ })
+	c.Advance(1500 * time.Millisecond)
+	assert.Equal(t, 1, n)
+
+	tk.Reset(time.Second)
+	c.Advance(900 * time.Millisecond)
+	assert.Equal(t, 1, n)
+	c.Advance(100 * time.Millisecond)
+	assert.Equal(t, 2, n)
+
+	tk.Stop()
+	c.Advance(time.Minute)
+	assert.Equal(t, 2, n)
+}
+
+func TestClock_Sleep(t *testing.T) {
+	start := time.Date(2021, 6, 5, 0, 0, 0, 0, time.UTC)
+	c := NewClock(start)
+	woke := make(chan time.Time)
+	for _, d := range []time.Duration{2 * time.Second, time.Second} {
+		go func(d time.Duration) {
+			c.Sleep(d)
+			woke <- c.Now()
+		}(d)
+	}
+	c.BlockUntil(2)
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-woke)
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(2*time.Second), <-woke)
+	c.Sleep(0)
+}